@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"backend/models"
+	"backend/repository/datastore"
+	"backend/services"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+func runBootstrap(cmd *cobra.Command, args []string) error {
+	username, _ := cmd.Flags().GetString("admin-username")
+	if username == "" {
+		username = viper.GetString("BOOTSTRAP_ADMIN_USERNAME")
+	}
+	password, _ := cmd.Flags().GetString("admin-password")
+	if password == "" {
+		password = viper.GetString("BOOTSTRAP_ADMIN_PASSWORD")
+	}
+
+	zapLogger, _ := zap.NewProductionConfig().Build()
+	env := &models.Env{
+		Logger:   zapLogger,
+		AuthDtos: &models.Auth{},
+		DbConn:   datastore.PostgeSQLConn,
+	}
+
+	result, err := services.Bootstrap(env, username, password)
+	if err != nil {
+		return err
+	}
+
+	if len(result.CreatedRoles) > 0 {
+		fmt.Printf("Created roles: %s\n", strings.Join(result.CreatedRoles, ", "))
+	} else {
+		fmt.Println("All roles already exist")
+	}
+	switch {
+	case result.AdminCreated:
+		fmt.Printf("Created admin user %s\n", username)
+	case result.AdminAssigned:
+		fmt.Printf("Granted admin role to existing user %s\n", username)
+	default:
+		fmt.Printf("Admin user %s already exists\n", username)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"backend/models"
+	"backend/repository/datastore"
+	"backend/services"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func runRecomputeDPD(cmd *cobra.Command, args []string) error {
+	asOf := time.Now()
+	if date, _ := cmd.Flags().GetString("date"); date != "" {
+		parsed, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return fmt.Errorf("invalid --date %q, expected YYYY-MM-DD", date)
+		}
+		asOf = parsed
+	}
+
+	zapLogger, _ := zap.NewProductionConfig().Build()
+	env := &models.Env{
+		Logger:   zapLogger,
+		AuthDtos: &models.Auth{},
+		DbConn:   datastore.PostgeSQLConn,
+	}
+
+	result, err := services.RecomputeDPD(env, asOf)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("DPD recomputed as of %s: %d cases processed, %d updated, %d snapshots stored\n",
+		result.AsOf.Format("2006-01-02"), result.Processed, result.Updated, result.Snapshotted)
+	return nil
+}
@@ -33,10 +33,75 @@ var (
 			server.Initialize()
 		},
 	}
+	migrate = &cobra.Command{
+		Use:   `migrate`,
+		Short: "Manage database schema migrations",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			loadConfigFiles()
+			if err := datastore.ConnectPostgeSQL(); err != nil {
+				panic(err)
+			}
+		},
+	}
+	migrateUp = &cobra.Command{
+		Use:   `up`,
+		Short: "Apply pending migrations",
+		RunE:  runMigrateUp,
+	}
+	migrateDown = &cobra.Command{
+		Use:   `down`,
+		Short: "Revert applied migrations",
+		RunE:  runMigrateDown,
+	}
+	bootstrap = &cobra.Command{
+		Use:   `bootstrap`,
+		Short: "Create the default roles and the first admin user",
+		PreRun: func(cmd *cobra.Command, args []string) {
+			loadConfigFiles()
+			if err := datastore.ConnectPostgeSQL(); err != nil {
+				panic(err)
+			}
+		},
+		RunE: runBootstrap,
+	}
+	recomputeDPD = &cobra.Command{
+		Use:   `recompute-dpd`,
+		Short: "Recalculate DPD and buckets of open cases and store the daily snapshot",
+		PreRun: func(cmd *cobra.Command, args []string) {
+			loadConfigFiles()
+			if err := datastore.ConnectPostgeSQL(); err != nil {
+				panic(err)
+			}
+		},
+		RunE: runRecomputeDPD,
+	}
+	migrateBaseline = &cobra.Command{
+		Use:   `baseline`,
+		Short: "Mark migrations up to a version as applied without running them",
+		RunE:  runMigrateBaseline,
+	}
+	migrateStatus = &cobra.Command{
+		Use:   `status`,
+		Short: "List migrations and whether they are applied",
+		RunE:  runMigrateStatus,
+	}
 )
 
 func RegisterCommands() {
+	migrateUp.Flags().Int("steps", 0, "number of migrations to apply, 0 applies all")
+	migrateDown.Flags().Int("steps", 1, "number of migrations to revert")
+	migrateBaseline.Flags().Int64("version", 1, "last migration already reflected in the schema")
+	migrate.AddCommand(migrateUp, migrateDown, migrateBaseline, migrateStatus)
+
 	rootCommand.AddCommand(serveHTTP)
+	rootCommand.AddCommand(migrate)
+
+	bootstrap.Flags().String("admin-username", "", "admin username, defaults to BOOTSTRAP_ADMIN_USERNAME")
+	bootstrap.Flags().String("admin-password", "", "admin password, defaults to BOOTSTRAP_ADMIN_PASSWORD")
+	rootCommand.AddCommand(bootstrap)
+
+	recomputeDPD.Flags().String("date", "", "day to compute DPD as of, YYYY-MM-DD, defaults to today")
+	rootCommand.AddCommand(recomputeDPD)
 	rootCommand.Execute()
 }
 
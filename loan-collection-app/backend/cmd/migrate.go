@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"backend/migrations"
+	"backend/repository/datastore"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func runMigrateUp(cmd *cobra.Command, args []string) error {
+	steps, _ := cmd.Flags().GetInt("steps")
+	migrator, err := migrations.NewMigrator(datastore.PostgeSQLConn)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(steps)
+	for _, migration := range applied {
+		fmt.Printf("Applied %04d_%s\n", migration.Version, migration.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		fmt.Println("No pending migrations")
+	}
+	return nil
+}
+
+func runMigrateDown(cmd *cobra.Command, args []string) error {
+	steps, _ := cmd.Flags().GetInt("steps")
+	if steps <= 0 {
+		return fmt.Errorf("steps must be at least 1")
+	}
+	migrator, err := migrations.NewMigrator(datastore.PostgeSQLConn)
+	if err != nil {
+		return err
+	}
+
+	reverted, err := migrator.Down(steps)
+	for _, migration := range reverted {
+		fmt.Printf("Reverted %04d_%s\n", migration.Version, migration.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(reverted) == 0 {
+		fmt.Println("No applied migrations")
+	}
+	return nil
+}
+
+func runMigrateBaseline(cmd *cobra.Command, args []string) error {
+	version, _ := cmd.Flags().GetInt64("version")
+	if version <= 0 {
+		return fmt.Errorf("version must be at least 1")
+	}
+	migrator, err := migrations.NewMigrator(datastore.PostgeSQLConn)
+	if err != nil {
+		return err
+	}
+
+	marked, err := migrator.Baseline(version)
+	for _, migration := range marked {
+		fmt.Printf("Marked %04d_%s as applied\n", migration.Version, migration.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(marked) == 0 {
+		fmt.Println("Nothing to mark, migrations are already applied")
+	}
+	return nil
+}
+
+func runMigrateStatus(cmd *cobra.Command, args []string) error {
+	migrator, err := migrations.NewMigrator(datastore.PostgeSQLConn)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%04d_%-30s %s\n", status.Version, status.Name, appliedAt)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package constants
+
+const (
+	AGENT_ASSIGNMENT_ROUND_ROBIN  = "ROUND_ROBIN"
+	AGENT_ASSIGNMENT_LEAST_LOADED = "LEAST_LOADED"
+	AGENT_ASSIGNMENT_TERRITORY    = "TERRITORY"
+)
+
+var AgentAssignmentStrategies = []string{
+	AGENT_ASSIGNMENT_ROUND_ROBIN,
+	AGENT_ASSIGNMENT_LEAST_LOADED,
+	AGENT_ASSIGNMENT_TERRITORY,
+}
+
+// DEFAULT_AGENT_MAX_OPEN_CASES caps the open cases of agents without a cap
+// of their own
+const DEFAULT_AGENT_MAX_OPEN_CASES = 200
+
+const (
+	AGENT_ASSIGNMENT_SKIP_NO_AGENTS          = "NO_ACTIVE_AGENTS"
+	AGENT_ASSIGNMENT_SKIP_NO_TERRITORY       = "NO_AGENT_FOR_TERRITORY"
+	AGENT_ASSIGNMENT_SKIP_AGENTS_AT_CAPACITY = "AGENTS_AT_CAPACITY"
+)
@@ -0,0 +1,14 @@
+package constants
+
+const (
+	DEFAULT_ALLOCATION_MAX_CASES                 = 10000
+	DEFAULT_ALLOCATION_PERFORMANCE_LOOKBACK_DAYS = 90
+	// Performance never scales an agency's share by more than this factor
+	// either way
+	ALLOCATION_MAX_PERFORMANCE_FACTOR = 4
+)
+
+const (
+	ALLOCATION_SKIP_NO_MATCHING_AGENCY = "NO_MATCHING_AGENCY"
+	ALLOCATION_SKIP_AT_CAPACITY        = "MATCHING_AGENCIES_AT_CAPACITY"
+)
@@ -0,0 +1,14 @@
+package constants
+
+// Levels of the assignment history of a case
+const (
+	ASSIGNMENT_LEVEL_AGENCY = "AGENCY"
+	ASSIGNMENT_LEVEL_AGENT  = "AGENT"
+)
+
+// Reasons recorded when an agency or agent stops holding a case
+const (
+	UNASSIGN_REASON_RECALLED    = "RECALLED"
+	UNASSIGN_REASON_REALLOCATED = "REALLOCATED"
+	UNASSIGN_REASON_REASSIGNED  = "REASSIGNED"
+)
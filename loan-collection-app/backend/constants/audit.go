@@ -0,0 +1,39 @@
+package constants
+
+// AUDIT_EVENT_CONTEXT_KEY holds the audit event of the current request in
+// env.RequestContext
+const AUDIT_EVENT_CONTEXT_KEY = "audit_event"
+
+const (
+	DEFAULT_AUDIT_PAGE_SIZE = 50
+	MAX_AUDIT_PAGE_SIZE     = 500
+)
+
+// Request IDs supplied by clients longer than this are replaced
+const MAX_REQUEST_ID_LENGTH = 64
+
+// Entity types recorded in the audit log
+const (
+	AUDIT_ENTITY_USER                   = "user"
+	AUDIT_ENTITY_ROLE                   = "role"
+	AUDIT_ENTITY_AGENCY                 = "agency"
+	AUDIT_ENTITY_LENDER                 = "lender"
+	AUDIT_ENTITY_CASE                   = "case"
+	AUDIT_ENTITY_PAYMENT                = "payment"
+	AUDIT_ENTITY_PERMISSION             = "permission"
+	AUDIT_ENTITY_ALLOCATION_RULE        = "allocation_rule"
+	AUDIT_ENTITY_PAYMENT_RECONCILIATION = "payment_reconciliation"
+)
+
+// AuditPathEntityTypes maps the first segment of an API path to the entity
+// type recorded when the service does not name one
+var AuditPathEntityTypes = map[string]string{
+	"users":       AUDIT_ENTITY_USER,
+	"roles":       AUDIT_ENTITY_ROLE,
+	"agencies":    AUDIT_ENTITY_AGENCY,
+	"lenders":     AUDIT_ENTITY_LENDER,
+	"cases":       AUDIT_ENTITY_CASE,
+	"payments":    AUDIT_ENTITY_PAYMENT,
+	"permissions": AUDIT_ENTITY_PERMISSION,
+	"allocation":  AUDIT_ENTITY_ALLOCATION_RULE,
+}
@@ -0,0 +1,20 @@
+package constants
+
+const (
+	DEFAULT_ACCESS_TOKEN_TTL_MINUTES = 15
+	DEFAULT_REFRESH_TOKEN_TTL_HOURS  = 720
+)
+
+const (
+	DEFAULT_LOGIN_MAX_ATTEMPTS_PER_USER  = 5
+	DEFAULT_LOGIN_MAX_ATTEMPTS_PER_IP    = 20
+	DEFAULT_LOGIN_FAILURE_WINDOW_MINUTES = 60
+	DEFAULT_LOGIN_LOCKOUT_BASE_SECONDS   = 60
+	DEFAULT_LOGIN_LOCKOUT_MAX_SECONDS    = 3600
+)
+
+const (
+	AUTH_EVENT_ACCOUNT_LOCKED   = "ACCOUNT_LOCKED"
+	AUTH_EVENT_IP_LOCKED        = "IP_LOCKED"
+	AUTH_EVENT_ACCOUNT_UNLOCKED = "ACCOUNT_UNLOCKED"
+)
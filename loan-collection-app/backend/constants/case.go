@@ -0,0 +1,160 @@
+package constants
+
+// Case lifecycle statuses
+const (
+	CASE_STATUS_NEW                 = "NEW"
+	CASE_STATUS_ALLOCATED_TO_AGENCY = "ALLOCATED_TO_AGENCY"
+	CASE_STATUS_ASSIGNED_TO_AGENT   = "ASSIGNED_TO_AGENT"
+	CASE_STATUS_IN_PROGRESS         = "IN_PROGRESS"
+	CASE_STATUS_PTP                 = "PTP"
+	CASE_STATUS_BROKEN_PTP          = "BROKEN_PTP"
+	CASE_STATUS_PARTIALLY_PAID      = "PARTIALLY_PAID"
+	CASE_STATUS_SETTLED             = "SETTLED"
+	CASE_STATUS_CLOSED              = "CLOSED"
+	CASE_STATUS_WITHDRAWN           = "WITHDRAWN"
+)
+
+// ClosedCaseStatuses are the statuses of cases no longer being collected
+var ClosedCaseStatuses = []string{
+	CASE_STATUS_SETTLED,
+	CASE_STATUS_CLOSED,
+	CASE_STATUS_WITHDRAWN,
+}
+
+// CaseStatusTransitions lists the statuses each status may move to. A case
+// can be paid, recalled or withdrawn at any point while it is open.
+var CaseStatusTransitions = map[string][]string{
+	CASE_STATUS_NEW: {
+		CASE_STATUS_ALLOCATED_TO_AGENCY,
+		CASE_STATUS_PARTIALLY_PAID, CASE_STATUS_SETTLED, CASE_STATUS_CLOSED, CASE_STATUS_WITHDRAWN,
+	},
+	CASE_STATUS_ALLOCATED_TO_AGENCY: {
+		CASE_STATUS_NEW, CASE_STATUS_ASSIGNED_TO_AGENT,
+		CASE_STATUS_PARTIALLY_PAID, CASE_STATUS_SETTLED, CASE_STATUS_CLOSED, CASE_STATUS_WITHDRAWN,
+	},
+	CASE_STATUS_ASSIGNED_TO_AGENT: {
+		CASE_STATUS_NEW, CASE_STATUS_ALLOCATED_TO_AGENCY, CASE_STATUS_IN_PROGRESS, CASE_STATUS_PTP,
+		CASE_STATUS_PARTIALLY_PAID, CASE_STATUS_SETTLED, CASE_STATUS_CLOSED, CASE_STATUS_WITHDRAWN,
+	},
+	CASE_STATUS_IN_PROGRESS: {
+		CASE_STATUS_NEW, CASE_STATUS_ALLOCATED_TO_AGENCY, CASE_STATUS_PTP,
+		CASE_STATUS_PARTIALLY_PAID, CASE_STATUS_SETTLED, CASE_STATUS_CLOSED, CASE_STATUS_WITHDRAWN,
+	},
+	CASE_STATUS_PTP: {
+		CASE_STATUS_NEW, CASE_STATUS_ALLOCATED_TO_AGENCY, CASE_STATUS_IN_PROGRESS, CASE_STATUS_BROKEN_PTP,
+		CASE_STATUS_PARTIALLY_PAID, CASE_STATUS_SETTLED, CASE_STATUS_CLOSED, CASE_STATUS_WITHDRAWN,
+	},
+	CASE_STATUS_BROKEN_PTP: {
+		CASE_STATUS_NEW, CASE_STATUS_ALLOCATED_TO_AGENCY, CASE_STATUS_IN_PROGRESS, CASE_STATUS_PTP,
+		CASE_STATUS_PARTIALLY_PAID, CASE_STATUS_SETTLED, CASE_STATUS_CLOSED, CASE_STATUS_WITHDRAWN,
+	},
+	CASE_STATUS_PARTIALLY_PAID: {
+		CASE_STATUS_NEW, CASE_STATUS_ALLOCATED_TO_AGENCY, CASE_STATUS_IN_PROGRESS, CASE_STATUS_PTP,
+		CASE_STATUS_SETTLED, CASE_STATUS_CLOSED, CASE_STATUS_WITHDRAWN,
+	},
+	CASE_STATUS_SETTLED: {
+		CASE_STATUS_CLOSED,
+	},
+	CASE_STATUS_CLOSED:    {},
+	CASE_STATUS_WITHDRAWN: {},
+}
+
+// ManualCaseStatusTargets lists, per role, the statuses a user may set by
+// hand. Allocation, assignment and recall statuses follow the assignment
+// endpoints and payment statuses follow posted payments, so none of them
+// can be set directly.
+var ManualCaseStatusTargets = map[string][]string{
+	"admin": {
+		CASE_STATUS_IN_PROGRESS, CASE_STATUS_PTP, CASE_STATUS_BROKEN_PTP,
+		CASE_STATUS_CLOSED, CASE_STATUS_WITHDRAWN,
+	},
+	"bank_admin": {
+		CASE_STATUS_IN_PROGRESS, CASE_STATUS_PTP, CASE_STATUS_BROKEN_PTP,
+		CASE_STATUS_CLOSED, CASE_STATUS_WITHDRAWN,
+	},
+	"agency_admin": {
+		CASE_STATUS_IN_PROGRESS, CASE_STATUS_PTP, CASE_STATUS_BROKEN_PTP,
+	},
+	"agent": {
+		CASE_STATUS_IN_PROGRESS, CASE_STATUS_PTP, CASE_STATUS_BROKEN_PTP,
+	},
+}
+
+// Reason codes recorded with every status change
+const (
+	CASE_REASON_AGENCY_ALLOCATION  = "AGENCY_ALLOCATION"
+	CASE_REASON_AGENT_ASSIGNMENT   = "AGENT_ASSIGNMENT"
+	CASE_REASON_RECALLED           = "RECALLED"
+	CASE_REASON_CUSTOMER_CONTACTED = "CUSTOMER_CONTACTED"
+	CASE_REASON_PROMISE_TO_PAY     = "PROMISE_TO_PAY"
+	CASE_REASON_PROMISE_BROKEN     = "PROMISE_BROKEN"
+	CASE_REASON_PAYMENT_RECEIVED   = "PAYMENT_RECEIVED"
+	CASE_REASON_FULLY_PAID         = "FULLY_PAID"
+	CASE_REASON_SETTLEMENT_AGREED  = "SETTLEMENT_AGREED"
+	CASE_REASON_LENDER_WITHDRAWAL  = "LENDER_WITHDRAWAL"
+	CASE_REASON_CUSTOMER_DECEASED  = "CUSTOMER_DECEASED"
+	CASE_REASON_FRAUD              = "FRAUD"
+	CASE_REASON_DUPLICATE          = "DUPLICATE"
+	CASE_REASON_OTHER              = "OTHER"
+)
+
+var CaseStatusReasons = []string{
+	CASE_REASON_AGENCY_ALLOCATION,
+	CASE_REASON_AGENT_ASSIGNMENT,
+	CASE_REASON_RECALLED,
+	CASE_REASON_CUSTOMER_CONTACTED,
+	CASE_REASON_PROMISE_TO_PAY,
+	CASE_REASON_PROMISE_BROKEN,
+	CASE_REASON_PAYMENT_RECEIVED,
+	CASE_REASON_FULLY_PAID,
+	CASE_REASON_SETTLEMENT_AGREED,
+	CASE_REASON_LENDER_WITHDRAWAL,
+	CASE_REASON_CUSTOMER_DECEASED,
+	CASE_REASON_FRAUD,
+	CASE_REASON_DUPLICATE,
+	CASE_REASON_OTHER,
+}
+
+const (
+	IMPORT_MODE_ALL_OR_NOTHING = "all_or_nothing"
+	IMPORT_MODE_ACCEPT_VALID   = "accept_valid"
+)
+
+const (
+	IMPORT_JOB_PENDING   = "PENDING"
+	IMPORT_JOB_RUNNING   = "RUNNING"
+	IMPORT_JOB_COMPLETED = "COMPLETED"
+	IMPORT_JOB_FAILED    = "FAILED"
+)
+
+const (
+	DEFAULT_CASE_IMPORT_BATCH_SIZE   = 1000
+	DEFAULT_CASE_UPLOAD_MAX_BYTES    = 100 << 20
+	CASE_IMPORT_MAX_REPORTED_ERRORS  = 1000
+	DEFAULT_IMPORT_JOB_STALE_MINUTES = 30
+	IMPORT_JOB_INTERRUPTED_ERROR     = "import was interrupted by a server restart"
+)
+
+const (
+	DEFAULT_CASE_PAGE_SIZE = 50
+	MAX_CASE_PAGE_SIZE     = 500
+)
+
+// Sort keys accepted by the case listings
+const (
+	CASE_SORT_DPD         = "dpd"
+	CASE_SORT_EMI_DATE    = "emi_date"
+	CASE_SORT_OUTSTANDING = "outstanding"
+	CASE_SORT_LOAN_ID     = "loan_id"
+	CASE_SORT_CREATED_AT  = "created_at"
+	CASE_SORT_UPDATED_AT  = "updated_at"
+)
+
+var CaseSortKeys = []string{
+	CASE_SORT_DPD,
+	CASE_SORT_EMI_DATE,
+	CASE_SORT_OUTSTANDING,
+	CASE_SORT_LOAN_ID,
+	CASE_SORT_CREATED_AT,
+	CASE_SORT_UPDATED_AT,
+}
@@ -0,0 +1,47 @@
+package constants
+
+// CaseImportColumnAliases lists the header names accepted for each case upload
+// field. Headers are matched case-insensitively with spaces and hyphens
+// treated as underscores. Extra aliases can be configured through
+// CASE_IMPORT_COLUMN_ALIASES.
+var CaseImportColumnAliases = map[string][]string{
+	"loan_id":                  {"loan_id", "loan_no", "loan_number", "loan_account_number"},
+	"external_customer_id":     {"external_customer_id", "customer_id", "cust_id"},
+	"emi_amount":               {"emi_amount", "emi"},
+	"principal_outstanding":    {"principal_outstanding", "pos"},
+	"interest_outstanding":     {"interest_outstanding"},
+	"emi_date":                 {"emi_date", "emi_due_date", "due_date"},
+	"dpd_bucket":               {"dpd_bucket", "bucket"},
+	"dpd":                      {"dpd", "days_past_due"},
+	"disbursal_date":           {"disbursal_date", "disbursement_date"},
+	"insurance_active":         {"insurance_active", "insurance"},
+	"loan_description":         {"loan_description", "product", "loan_type"},
+	"emis_paid_till_date":      {"emis_paid_till_date", "emis_paid"},
+	"emis_pending":             {"emis_pending"},
+	"bounce_charges":           {"bounce_charges"},
+	"nach_presentation_status": {"nach_presentation_status", "nach_status"},
+	"customer_name":            {"customer_name", "name"},
+	"customer_phones":          {"customer_phones", "customer_phone", "phone", "mobile"},
+	"customer_email":           {"customer_email", "email"},
+	"customer_address":         {"customer_address", "address"},
+	"customer_city":            {"customer_city", "city"},
+	"customer_state":           {"customer_state", "state"},
+	"customer_pincode":         {"customer_pincode", "pincode", "pin_code"},
+}
+
+// CaseImportRequiredColumns must be present in every case upload
+var CaseImportRequiredColumns = []string{
+	"loan_id",
+	"external_customer_id",
+	"emi_amount",
+	"principal_outstanding",
+	"emi_date",
+	"dpd",
+}
+
+// CaseImportDateLayouts are the date formats accepted in case uploads
+var CaseImportDateLayouts = []string{
+	"2006-01-02",
+	"02-01-2006",
+	"02/01/2006",
+}
@@ -0,0 +1,7 @@
+package constants
+
+const (
+	DEFAULT_DPD_RECOMPUTE_BATCH_SIZE = 1000
+	// DEFAULT_DPD_RECOMPUTE_TIME is the local time of day the scheduler runs at
+	DEFAULT_DPD_RECOMPUTE_TIME = "02:00"
+)
@@ -0,0 +1,20 @@
+package constants
+
+// Formats a listing can be exported in, chosen by the format query
+// parameter or the Accept header
+const (
+	EXPORT_FORMAT_CSV  = "csv"
+	EXPORT_FORMAT_XLSX = "xlsx"
+)
+
+const (
+	CSV_CONTENT_TYPE  = "text/csv"
+	XLSX_CONTENT_TYPE = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// Rows fetched per query while streaming an export
+const EXPORT_BATCH_SIZE = 500
+
+// Leading characters that make spreadsheet apps treat a text cell as a
+// formula
+const EXPORT_FORMULA_PREFIXES = "=+-@\t\r"
@@ -0,0 +1,23 @@
+package constants
+
+const (
+	PAYMENT_AMOUNT_EMI     = "EMI"
+	PAYMENT_AMOUNT_OVERDUE = "OVERDUE"
+	PAYMENT_AMOUNT_CUSTOM  = "CUSTOM"
+)
+
+const (
+	PAYMENT_LINK_PENDING   = "PENDING"
+	PAYMENT_LINK_CREATED   = "CREATED"
+	PAYMENT_LINK_FAILED    = "FAILED"
+	PAYMENT_LINK_PAID      = "PAID"
+	PAYMENT_LINK_EXPIRED   = "EXPIRED"
+	PAYMENT_LINK_CANCELLED = "CANCELLED"
+)
+
+const DEFAULT_PAYMENT_LINK_EXPIRY_HOURS = 72
+
+const (
+	PAYMENT_SOURCE_PAYMENT_LINK        = "PAYMENT_LINK"
+	PAYMENT_SOURCE_BANK_RECONCILIATION = "BANK_RECONCILIATION"
+)
@@ -0,0 +1,52 @@
+package constants
+
+// PaymentReconciliationColumnAliases lists the header names accepted for
+// each column of a lender payment file, matched like case upload headers
+var PaymentReconciliationColumnAliases = map[string][]string{
+	"loan_id":   {"loan_id", "loan_no", "loan_number", "loan_account_number"},
+	"amount":    {"amount", "paid_amount", "payment_amount", "txn_amount"},
+	"date":      {"date", "payment_date", "paid_at", "txn_date", "value_date"},
+	"mode":      {"mode", "payment_mode", "channel"},
+	"reference": {"reference", "payment_reference", "reference_no", "utr", "txn_reference"},
+}
+
+// PaymentReconciliationRequiredColumns must be present in every payment file
+var PaymentReconciliationRequiredColumns = []string{
+	"loan_id",
+	"amount",
+	"date",
+}
+
+// Outcomes of a payment file line. A possible duplicate is posted but
+// flagged for review: it has no reference and repeats the loan, date, amount
+// and mode of an earlier line of the file.
+const (
+	RECONCILIATION_LINE_POSTED             = "POSTED"
+	RECONCILIATION_LINE_POSSIBLE_DUPLICATE = "POSSIBLE_DUPLICATE"
+	RECONCILIATION_LINE_UNMATCHED          = "UNMATCHED"
+	RECONCILIATION_LINE_DUPLICATE          = "DUPLICATE"
+	RECONCILIATION_LINE_INVALID            = "INVALID"
+)
+
+var ReconciliationLineStatuses = []string{
+	RECONCILIATION_LINE_POSTED,
+	RECONCILIATION_LINE_POSSIBLE_DUPLICATE,
+	RECONCILIATION_LINE_UNMATCHED,
+	RECONCILIATION_LINE_DUPLICATE,
+	RECONCILIATION_LINE_INVALID,
+}
+
+// Limits of the values a payment file line carries, set by the columns the
+// line and its payment are stored in
+const (
+	RECONCILIATION_MAX_LOAN_ID_LENGTH   = 50
+	RECONCILIATION_MAX_MODE_LENGTH      = 50
+	RECONCILIATION_MAX_REFERENCE_LENGTH = 255
+	// RECONCILIATION_MAX_AMOUNT is the largest amount a NUMERIC(10, 2) holds
+	RECONCILIATION_MAX_AMOUNT = 99999999.99
+)
+
+const (
+	DEFAULT_RECONCILIATION_PAGE_SIZE = 100
+	MAX_RECONCILIATION_PAGE_SIZE     = 1000
+)
@@ -0,0 +1,4 @@
+package constants
+
+// DEFAULT_RATE_LIMIT_PER_MINUTE applies to routes without an entry in RATE_LIMIT_ROUTES
+const DEFAULT_RATE_LIMIT_PER_MINUTE = 120
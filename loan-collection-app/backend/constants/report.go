@@ -0,0 +1,38 @@
+package constants
+
+// Groupings of the collection performance report
+const (
+	REPORT_GROUP_AGENCY     = "agency"
+	REPORT_GROUP_AGENT      = "agent"
+	REPORT_GROUP_DPD_BUCKET = "dpd_bucket"
+	REPORT_GROUP_LOAN_TYPE  = "loan_type"
+)
+
+var ReportGroupings = []string{
+	REPORT_GROUP_AGENCY,
+	REPORT_GROUP_AGENT,
+	REPORT_GROUP_DPD_BUCKET,
+	REPORT_GROUP_LOAN_TYPE,
+}
+
+// ResolvedCaseStatuses are the statuses that count a case as resolved
+var ResolvedCaseStatuses = []string{
+	CASE_STATUS_SETTLED,
+	CASE_STATUS_CLOSED,
+}
+
+// ContactedDispositions are the trail dispositions that mean the customer
+// was reached
+var ContactedDispositions = []string{
+	DISPOSITION_CONTACTED,
+	DISPOSITION_PROMISE_TO_PAY,
+	DISPOSITION_REFUSED_TO_PAY,
+	DISPOSITION_PAID,
+}
+
+const (
+	// A promise to pay is kept when a payment arrives by the promised date
+	// plus this many days
+	DEFAULT_PTP_GRACE_DAYS = 2
+	MAX_REPORT_RANGE_DAYS  = 366
+)
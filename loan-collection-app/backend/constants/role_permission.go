@@ -1,5 +1,8 @@
 package constants
 
+// RolePermissionsMap holds the permissions granted to the built-in roles
+// when they are first created. Afterwards grants live in the
+// role_permissions table and are managed through the API.
 var RolePermissionsMap = map[string][]string{
 	"admin": {
 		"view_users",
@@ -27,6 +30,23 @@ var RolePermissionsMap = map[string][]string{
 		"view_unassigned_cases",
 		"assign_cases",
 		"view_all_agency_users",
+		"view_permissions",
+		"manage_role_permissions",
+		"view_all_cases",
+		"view_lenders",
+		"create_lender",
+		"assign_lender_user",
+		"unlock_user",
+		"update_case_status",
+		"view_allocation_rules",
+		"manage_allocation_rules",
+		"allocate_cases",
+		"recall_cases",
+		"reassign_agency_cases",
+		"view_audit_events",
+		"view_reports",
+		"export_cases",
+		"reconcile_payments",
 	},
 	"agency_admin": {
 		"view_agency_cases",
@@ -41,12 +61,19 @@ var RolePermissionsMap = map[string][]string{
 		"view_all_agency_users",
 		"assign_agency_user",
 		"view_unassigned_cases",
+		"view_my_cases",
+		"view_trails",
+		"update_case_status",
+		"reassign_agency_cases",
+		"view_reports",
+		"export_cases",
 	},
 	"agent": {
 		"view_my_cases",
 		"view_trails",
 		"generate_payment_link",
 		"add_trail",
+		"update_case_status",
 	},
 	"bank_admin": {
 		"upload_cases",
@@ -55,6 +82,17 @@ var RolePermissionsMap = map[string][]string{
 		"view_agency_users",
 		"assign_agency_cases",
 		"view_agencies",
+		"view_lender_cases",
+		"view_my_cases",
+		"view_trails",
+		"update_case_status",
+		"view_allocation_rules",
+		"manage_allocation_rules",
+		"allocate_cases",
+		"recall_cases",
+		"view_reports",
+		"export_cases",
+		"reconcile_payments",
 	},
 	"default": {
 		"view_my_permissions",
@@ -0,0 +1,19 @@
+package constants
+
+const (
+	DISPOSITION_CONTACTED      = "CONTACTED"
+	DISPOSITION_NOT_REACHABLE  = "NOT_REACHABLE"
+	DISPOSITION_WRONG_NUMBER   = "WRONG_NUMBER"
+	DISPOSITION_PROMISE_TO_PAY = "PROMISE_TO_PAY"
+	DISPOSITION_REFUSED_TO_PAY = "REFUSED_TO_PAY"
+	DISPOSITION_PAID           = "PAID"
+)
+
+var TrailDispositions = []string{
+	DISPOSITION_CONTACTED,
+	DISPOSITION_NOT_REACHABLE,
+	DISPOSITION_WRONG_NUMBER,
+	DISPOSITION_PROMISE_TO_PAY,
+	DISPOSITION_REFUSED_TO_PAY,
+	DISPOSITION_PAID,
+}
@@ -140,7 +140,7 @@ func AssignCaseToUser(c *gin.Context) {
 	}
 
 	if err := services.AssignCaseToUser(env, &mapping); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(agentAssignmentErrorCode(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -148,11 +148,13 @@ func AssignCaseToUser(c *gin.Context) {
 }
 
 type ListAgencyUsersResponse struct {
-	ID        string  `json:"id"`
-	Username  string  `json:"username"`
-	Email     string  `json:"email"`
-	Role      string  `json:"role"`
-	ManagerID *string `json:"manager_id,omitempty"`
+	ID           string   `json:"id"`
+	Username     string   `json:"username"`
+	Email        string   `json:"email"`
+	Role         string   `json:"role"`
+	ManagerID    *string  `json:"manager_id,omitempty"`
+	MaxOpenCases *int     `json:"max_open_cases"`
+	Territories  []string `json:"territories"`
 }
 
 type UnassignedUsersResponse struct {
@@ -166,6 +168,18 @@ func GetAgencyUsers(c *gin.Context) {
 	env := val.(*models.Env)
 
 	agencyID := c.Param("agency_id")
+	format, err := exportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if format != "" {
+		writeExport(c, format, "agency-users", agencyUserExportColumns, exportAll(func() ([]models.AgencyUserDetails, error) {
+			return services.ListAgencyUsers(env, agencyID)
+		}))
+		return
+	}
+
 	users, err := services.ListAgencyUsers(env, agencyID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -175,11 +189,13 @@ func GetAgencyUsers(c *gin.Context) {
 	response := []ListAgencyUsersResponse{}
 	for _, user := range users {
 		response = append(response, ListAgencyUsersResponse{
-			ID:        user.UserID,
-			Username:  user.Username,
-			Email:     user.Email,
-			Role:      user.AgencyRole,
-			ManagerID: user.ManagerID,
+			ID:           user.UserID,
+			Username:     user.Username,
+			Email:        user.Email,
+			Role:         user.AgencyRole,
+			ManagerID:    user.ManagerID,
+			MaxOpenCases: user.MaxOpenCases,
+			Territories:  user.Territories,
 		})
 	}
 
@@ -3,7 +3,9 @@ package handlers
 import (
 	"backend/models"
 	"backend/services"
+	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -40,7 +42,24 @@ func GetAgencyCasesHandler(c *gin.Context) {
 	val, _ := c.Get("env")
 	env := val.(*models.Env)
 
-	cases, err := services.ListCases(env, env.AuthDtos.User.ID)
+	filter, err := parseCaseListQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	format, err := exportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if format != "" {
+		writeExport(c, format, "agency-cases", agencyCaseExportColumns, exportCaseBatch(filter, func(filter models.CaseFilter) ([]models.CaseWithAssignee, int64, error) {
+			return services.ListCases(env, env.AuthDtos.User.ID, filter)
+		}))
+		return
+	}
+
+	cases, total, err := services.ListCases(env, env.AuthDtos.User.ID, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -66,22 +85,17 @@ func GetAgencyCasesHandler(c *gin.Context) {
 			BounceCharges:          caseData.BounceCharges,
 			NachPresentationStatus: caseData.NachPresentationStatus,
 		}
-		user, err := services.GetAssignedUserByCaseID(env, caseData.ID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		if user != nil {
+		if caseData.AssigneeID != nil {
 			agencyCaseResponse.AssignedTo = &AgencyCaseResponseUser{
-				Id:       user.ID,
-				Username: user.Username,
-				Email:    user.Email,
+				Id:       *caseData.AssigneeID,
+				Username: *caseData.AssigneeUsername,
+				Email:    caseData.AssigneeEmail,
 			}
 		}
 		response = append(response, agencyCaseResponse)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": response})
+	c.JSON(http.StatusOK, gin.H{"data": response, "pagination": newPagination(filter.Page, filter.PageSize, total)})
 }
 
 type AssignCaseRequest struct {
@@ -105,7 +119,7 @@ func AssignAgencyCaseHandler(c *gin.Context) {
 	}
 
 	if err := services.AssignCaseToUser(env, mapping); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(agentAssignmentErrorCode(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -116,6 +130,18 @@ func GetMyAgencyUsersHandler(c *gin.Context) {
 	val, _ := c.Get("env")
 	env := val.(*models.Env)
 
+	format, err := exportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if format != "" {
+		writeExport(c, format, "agency-users", agencyUserExportColumns, exportAll(func() ([]models.AgencyUserDetails, error) {
+			return services.ListMyAgencyUsers(env, env.AuthDtos.User.ID)
+		}))
+		return
+	}
+
 	users, err := services.ListMyAgencyUsers(env, env.AuthDtos.User.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -123,3 +149,107 @@ func GetMyAgencyUsersHandler(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"data": users})
 }
+
+type AgentAssignmentPreviewRequest struct {
+	AgencyID string   `json:"agency_id"`
+	Strategy string   `json:"strategy" binding:"required"`
+	CaseIDs  []string `json:"case_ids"`
+	Limit    int      `json:"limit"`
+}
+
+// POST /api/v1/agencies/cases/auto-assign/preview
+func PreviewAgentAssignmentHandler(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	var req AgentAssignmentPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := services.PreviewAgentAssignment(env, services.AgentAssignmentRequest{
+		AgencyID: req.AgencyID,
+		Strategy: strings.ToUpper(req.Strategy),
+		CaseIDs:  req.CaseIDs,
+		Limit:    req.Limit,
+	})
+	if err != nil {
+		c.JSON(agentAssignmentErrorCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+type AgentAssignmentCommitRequest struct {
+	AgencyID    string                   `json:"agency_id"`
+	Assignments []models.AgentAssignment `json:"assignments" binding:"required,min=1,dive"`
+}
+
+// POST /api/v1/agencies/cases/auto-assign
+func CommitAgentAssignmentHandler(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	var req AgentAssignmentCommitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.CommitAgentAssignment(env, req.AgencyID, req.Assignments); err != nil {
+		c.JSON(agentAssignmentErrorCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Cases assigned successfully",
+		"assigned": len(req.Assignments),
+	})
+}
+
+type AgentAssignmentProfileRequest struct {
+	AgencyID     string   `json:"agency_id"`
+	MaxOpenCases *int     `json:"max_open_cases"`
+	Territories  []string `json:"territories"`
+}
+
+// PUT /api/v1/agencies/users/:user_id/assignment-profile
+func UpdateAgentAssignmentProfileHandler(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	var req AgentAssignmentProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	territories := nonNilStrings(req.Territories)
+	err := services.UpdateAgentAssignmentProfile(env, req.AgencyID, c.Param("user_id"), req.MaxOpenCases, territories)
+	if err != nil {
+		c.JSON(agentAssignmentErrorCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":        c.Param("user_id"),
+		"max_open_cases": req.MaxOpenCases,
+		"territories":    territories,
+	})
+}
+
+// agentAssignmentErrorCode maps auto-assignment errors to a response status
+func agentAssignmentErrorCode(err error) int {
+	var transitionErr *services.CaseTransitionError
+	switch {
+	case errors.Is(err, services.ErrNoAgency):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrUnknownStrategy), errors.Is(err, services.ErrInvalidAgentProfile):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrInvalidAssignment), errors.As(err, &transitionErr):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"backend/models"
+	"backend/services"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AllocationRuleResponse struct {
+	Id         string    `json:"id"`
+	AgencyID   string    `json:"agency_id"`
+	DPDBuckets []string  `json:"dpd_buckets"`
+	LoanTypes  []string  `json:"loan_types"`
+	Pincodes   []string  `json:"pincodes"`
+	Capacity   *int      `json:"capacity"`
+	Weight     float64   `json:"weight"`
+	IsActive   bool      `json:"is_active"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func newAllocationRuleResponse(rule models.AllocationRule) AllocationRuleResponse {
+	return AllocationRuleResponse{
+		Id:         rule.ID,
+		AgencyID:   rule.AgencyID,
+		DPDBuckets: rule.DPDBuckets,
+		LoanTypes:  rule.LoanTypes,
+		Pincodes:   rule.Pincodes,
+		Capacity:   rule.Capacity,
+		Weight:     rule.Weight,
+		IsActive:   rule.IsActive,
+		UpdatedAt:  rule.UpdatedAt,
+	}
+}
+
+// GET /api/v1/allocation/rules
+func ListAllocationRules(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	rules, err := services.ListAllocationRules(env)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := []AllocationRuleResponse{}
+	for _, rule := range rules {
+		response = append(response, newAllocationRuleResponse(rule))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+type SaveAllocationRuleRequest struct {
+	DPDBuckets []string `json:"dpd_buckets"`
+	LoanTypes  []string `json:"loan_types"`
+	Pincodes   []string `json:"pincodes"`
+	Capacity   *int     `json:"capacity"`
+	Weight     *float64 `json:"weight"`
+	IsActive   *bool    `json:"is_active"`
+}
+
+// PUT /api/v1/allocation/rules/:agency_id
+func SaveAllocationRule(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	var req SaveAllocationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule := models.AllocationRule{
+		AgencyID:   c.Param("agency_id"),
+		DPDBuckets: nonNilStrings(req.DPDBuckets),
+		LoanTypes:  nonNilStrings(req.LoanTypes),
+		Pincodes:   nonNilStrings(req.Pincodes),
+		Capacity:   req.Capacity,
+		Weight:     1,
+		IsActive:   true,
+	}
+	if req.Weight != nil {
+		rule.Weight = *req.Weight
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := services.SaveAllocationRule(env, &rule); err != nil {
+		c.JSON(allocationErrorCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newAllocationRuleResponse(rule))
+}
+
+type PreviewAllocationRequest struct {
+	CaseIDs        []string `json:"case_ids"`
+	Limit          int      `json:"limit"`
+	UsePerformance bool     `json:"use_performance"`
+}
+
+// POST /api/v1/cases/allocate/preview
+func PreviewAllocation(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	var req PreviewAllocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := services.PreviewAllocation(env, services.AllocationRequest{
+		CaseIDs:        req.CaseIDs,
+		Limit:          req.Limit,
+		UsePerformance: req.UsePerformance,
+	})
+	if err != nil {
+		c.JSON(allocationErrorCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+type CommitAllocationRequest struct {
+	Assignments []models.AllocationAssignment `json:"assignments" binding:"required,min=1,dive"`
+}
+
+// POST /api/v1/cases/allocate
+func CommitAllocation(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	var req CommitAllocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.CommitAllocation(env, req.Assignments); err != nil {
+		c.JSON(allocationErrorCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Cases allocated successfully",
+		"allocated": len(req.Assignments),
+	})
+}
+
+// allocationErrorCode maps allocation errors to a response status
+func allocationErrorCode(err error) int {
+	var transitionErr *services.CaseTransitionError
+	switch {
+	case errors.Is(err, services.ErrNoLender):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrInvalidAllocationRule):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrInvalidAllocation), errors.As(err, &transitionErr):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"backend/constants"
+	"backend/models"
+	"backend/services"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditEventQuery holds the query parameters of the audit log listing. from
+// and to accept a YYYY-MM-DD date or an RFC 3339 time; a date in to includes
+// the whole day.
+type AuditEventQuery struct {
+	Page       int    `form:"page"`
+	PageSize   int    `form:"page_size"`
+	ActorID    string `form:"actor_id"`
+	Action     string `form:"action"`
+	EntityType string `form:"entity_type"`
+	EntityID   string `form:"entity_id"`
+	RequestID  string `form:"request_id"`
+	From       string `form:"from"`
+	To         string `form:"to"`
+}
+
+// GET /api/v1/audit-events
+func ListAuditEvents(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	filter, err := parseAuditEventQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	events, total, err := services.ListAuditEvents(env, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": events, "pagination": newPagination(filter.Page, filter.PageSize, total)})
+}
+
+func parseAuditEventQuery(c *gin.Context) (models.AuditEventFilter, error) {
+	var query AuditEventQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		return models.AuditEventFilter{}, err
+	}
+	if query.ActorID != "" && !isUUID(query.ActorID) {
+		return models.AuditEventFilter{}, fmt.Errorf("invalid actor_id")
+	}
+
+	filter := models.AuditEventFilter{
+		ActorID:    optionalString(query.ActorID),
+		Action:     optionalString(query.Action),
+		EntityType: optionalString(query.EntityType),
+		EntityID:   optionalString(query.EntityID),
+		RequestID:  optionalString(query.RequestID),
+		Page:       max(query.Page, 1),
+		PageSize:   query.PageSize,
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = constants.DEFAULT_AUDIT_PAGE_SIZE
+	}
+	if filter.PageSize > constants.MAX_AUDIT_PAGE_SIZE {
+		return filter, fmt.Errorf("page_size cannot exceed %d", constants.MAX_AUDIT_PAGE_SIZE)
+	}
+
+	var err error
+	if filter.From, err = parseAuditTime(query.From, false); err != nil {
+		return filter, fmt.Errorf("invalid from")
+	}
+	if filter.To, err = parseAuditTime(query.To, true); err != nil {
+		return filter, fmt.Errorf("invalid to")
+	}
+	return filter, nil
+}
+
+// parseAuditTime parses a date or a time, returning nil when empty. With
+// endOfDay a date stands for the start of the next day.
+func parseAuditTime(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	date, err := parseOptionalDate(value)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		next := date.AddDate(0, 0, 1)
+		return &next, nil
+	}
+	return date, nil
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
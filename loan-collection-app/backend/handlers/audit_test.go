@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseAuditEventQueryActorID(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr bool
+	}{
+		{"", false},
+		{"actor_id=6f1c2b9e-3d4a-4c5b-8e7f-1a2b3c4d5e6f", false},
+		{"actor_id=abc", true},
+		{"actor_id=1%27%20OR%201=1", true},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/audit-events?"+tt.query, nil)
+		filter, err := parseAuditEventQuery(c)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseAuditEventQuery(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+		}
+		if err == nil && tt.query != "" && filter.ActorID == nil {
+			t.Errorf("parseAuditEventQuery(%q) dropped actor_id", tt.query)
+		}
+	}
+}
@@ -4,7 +4,11 @@ import (
 	"backend/constants"
 	"backend/models"
 	"backend/services"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -31,14 +35,98 @@ func LoginHandler(c *gin.Context) {
 		return
 	}
 
-	token, err := services.Login(env, credentials.Username, credentials.Password)
-	if err != nil {
+	tokens, err := services.Login(env, credentials.Username, credentials.Password, c.ClientIP())
+	var lockedErr *services.LoginLockedError
+	if errors.As(err, &lockedErr) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": lockedErr.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrInvalidCredentials) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Logged in successfully",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// RefreshTokenHandler exchanges a refresh token for a new token pair
+func RefreshTokenHandler(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := services.RefreshTokens(env, req.RefreshToken)
+	if errors.Is(err, services.ErrInvalidRefreshToken) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Logged in successfully",
-		"token":   token,
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 	})
 }
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// LogoutHandler revokes the caller's access token and the refresh token sent with it
+func LogoutHandler(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	var req LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	jti, _ := env.RequestContext["token_id"].(string)
+	expiresAt, _ := env.RequestContext["token_expires_at"].(time.Time)
+	if err := services.Logout(env, env.AuthDtos.User.ID, jti, expiresAt, req.RefreshToken); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// LogoutAllHandler revokes every session of the caller, on all devices
+func LogoutAllHandler(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	if err := services.LogoutAll(env, env.AuthDtos.User.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out of all sessions"})
+}
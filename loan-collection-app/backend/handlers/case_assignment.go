@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"backend/models"
+	"backend/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RecallCasesRequest struct {
+	CaseIDs []string `json:"case_ids" binding:"required,min=1"`
+	Remarks string   `json:"remarks"`
+}
+
+// POST /api/v1/cases/recall
+func RecallCasesHandler(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	var req RecallCasesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.RecallCases(env, req.CaseIDs, req.Remarks); err != nil {
+		c.JSON(caseAssignmentErrorCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cases recalled successfully"})
+}
+
+type ReallocateCasesRequest struct {
+	CaseIDs  []string `json:"case_ids" binding:"required,min=1"`
+	AgencyID string   `json:"agency_id" binding:"required"`
+	Remarks  string   `json:"remarks"`
+}
+
+// POST /api/v1/cases/reallocate
+func ReallocateCasesHandler(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	var req ReallocateCasesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.ReallocateCases(env, req.CaseIDs, req.AgencyID, req.Remarks); err != nil {
+		c.JSON(caseAssignmentErrorCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cases reallocated successfully"})
+}
+
+type RecallAgentCasesRequest struct {
+	AgencyID string   `json:"agency_id"`
+	CaseIDs  []string `json:"case_ids" binding:"required,min=1"`
+	Remarks  string   `json:"remarks"`
+}
+
+// POST /api/v1/agencies/cases/recall
+func RecallAgentCasesHandler(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	var req RecallAgentCasesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.RecallAgentCases(env, req.AgencyID, req.CaseIDs, req.Remarks); err != nil {
+		c.JSON(caseAssignmentErrorCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cases recalled successfully"})
+}
+
+type ReassignCasesRequest struct {
+	AgencyID string   `json:"agency_id"`
+	CaseIDs  []string `json:"case_ids" binding:"required,min=1"`
+	UserID   string   `json:"user_id" binding:"required"`
+	Remarks  string   `json:"remarks"`
+}
+
+// POST /api/v1/agencies/cases/reassign
+func ReassignCasesHandler(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	var req ReassignCasesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.ReassignCases(env, req.AgencyID, req.CaseIDs, req.UserID, req.Remarks); err != nil {
+		c.JSON(caseAssignmentErrorCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cases reassigned successfully"})
+}
+
+// GET /api/v1/cases/:caseID/assignment-history
+func GetCaseAssignmentHistory(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	history, err := services.GetCaseAssignmentHistory(env, c.Param("caseID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": history})
+}
+
+// caseAssignmentErrorCode maps recall and reassignment errors to a response
+// status
+func caseAssignmentErrorCode(err error) int {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, services.ErrNoLender) {
+		return http.StatusForbidden
+	}
+	return agentAssignmentErrorCode(err)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"backend/constants"
+	"backend/models"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CaseListQuery holds the query parameters shared by the case listings.
+// List parameters accept repeated values or a comma separated list, dates
+// are YYYY-MM-DD and order is asc or desc.
+type CaseListQuery struct {
+	Page           int      `form:"page"`
+	PageSize       int      `form:"page_size"`
+	Sort           string   `form:"sort"`
+	Order          string   `form:"order"`
+	DPDBucket      []string `form:"dpd_bucket"`
+	DPDMin         *int     `form:"dpd_min"`
+	DPDMax         *int     `form:"dpd_max"`
+	CaseStatus     []string `form:"case_status"`
+	NachStatus     []string `form:"nach_status"`
+	EMIDateFrom    string   `form:"emi_date_from"`
+	EMIDateTo      string   `form:"emi_date_to"`
+	AssignedTo     string   `form:"assigned_to"`
+	OutstandingMin *float64 `form:"outstanding_min"`
+	OutstandingMax *float64 `form:"outstanding_max"`
+}
+
+type Pagination struct {
+	Page       int   `json:"page"`
+	PageSize   int   `json:"page_size"`
+	Total      int64 `json:"total"`
+	TotalPages int64 `json:"total_pages"`
+}
+
+// parseCaseListQuery reads the paging, filter and sort parameters of a case listing
+func parseCaseListQuery(c *gin.Context) (models.CaseFilter, error) {
+	var query CaseListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		return models.CaseFilter{}, err
+	}
+
+	filter := models.CaseFilter{
+		DPDBuckets:     splitQueryValues(query.DPDBucket),
+		DPDMin:         query.DPDMin,
+		DPDMax:         query.DPDMax,
+		CaseStatuses:   splitQueryValues(query.CaseStatus),
+		NachStatuses:   splitQueryValues(query.NachStatus),
+		OutstandingMin: query.OutstandingMin,
+		OutstandingMax: query.OutstandingMax,
+		SortBy:         query.Sort,
+		Page:           max(query.Page, 1),
+		PageSize:       query.PageSize,
+	}
+
+	if filter.PageSize <= 0 {
+		filter.PageSize = constants.DEFAULT_CASE_PAGE_SIZE
+	}
+	if filter.PageSize > constants.MAX_CASE_PAGE_SIZE {
+		return filter, fmt.Errorf("page_size cannot exceed %d", constants.MAX_CASE_PAGE_SIZE)
+	}
+
+	if filter.SortBy == "" {
+		filter.SortBy = constants.CASE_SORT_CREATED_AT
+	}
+	if !slices.Contains(constants.CaseSortKeys, filter.SortBy) {
+		return filter, fmt.Errorf("sort must be one of %s", strings.Join(constants.CaseSortKeys, ", "))
+	}
+	switch strings.ToLower(query.Order) {
+	case "", "asc":
+	case "desc":
+		filter.SortDescending = true
+	default:
+		return filter, fmt.Errorf("order must be asc or desc")
+	}
+
+	var err error
+	if filter.EMIDateFrom, err = parseOptionalDate(query.EMIDateFrom); err != nil {
+		return filter, fmt.Errorf("invalid emi_date_from")
+	}
+	if filter.EMIDateTo, err = parseOptionalDate(query.EMIDateTo); err != nil {
+		return filter, fmt.Errorf("invalid emi_date_to")
+	}
+	if query.AssignedTo != "" {
+		if !isUUID(query.AssignedTo) {
+			return filter, fmt.Errorf("invalid assigned_to")
+		}
+		filter.AssignedUserID = &query.AssignedTo
+	}
+
+	return filter, nil
+}
+
+func splitQueryValues(values []string) []string {
+	result := []string{}
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				result = append(result, part)
+			}
+		}
+	}
+	return result
+}
+
+func newPagination(page, pageSize int, total int64) Pagination {
+	return Pagination{
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: (total + int64(pageSize) - 1) / int64(pageSize),
+	}
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseCaseListQueryAssignedTo(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr bool
+	}{
+		{"", false},
+		{"assigned_to=6f1c2b9e-3d4a-4c5b-8e7f-1a2b3c4d5e6f", false},
+		{"assigned_to=abc", true},
+		{"assigned_to=1%27%20OR%201=1", true},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/cases?"+tt.query, nil)
+		filter, err := parseCaseListQuery(c)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseCaseListQuery(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+		}
+		if err == nil && tt.query != "" && filter.AssignedUserID == nil {
+			t.Errorf("parseCaseListQuery(%q) dropped assigned_to", tt.query)
+		}
+	}
+}
@@ -1,23 +1,38 @@
 package handlers
 
 import (
+	"backend/constants"
 	"backend/models"
 	"backend/services"
 	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"mime/multipart"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
 )
 
-// UploadCasesHandler handles the CSV upload and case creation
+// UploadCasesHandler handles the CSV upload and case creation.
+// With async=true the file is imported in the background and the response
+// carries the job ID to poll.
 func UploadCasesHandler(c *gin.Context) {
 	val, _ := c.Get("env")
 	env := val.(*models.Env)
 
-	file, err := c.FormFile("file")
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+	file, ok := uploadedFile(c)
+	if !ok {
+		return
+	}
+
+	mode := c.DefaultPostForm("mode", constants.IMPORT_MODE_ALL_OR_NOTHING)
+
+	if c.PostForm("async") == "true" {
+		startCaseImportJob(c, env, file, mode)
 		return
 	}
 
@@ -29,24 +44,135 @@ func UploadCasesHandler(c *gin.Context) {
 	defer openedFile.Close()
 
 	reader := csv.NewReader(openedFile)
-	records, err := reader.ReadAll()
+	report, err := services.ImportCasesFromCSV(env, reader, mode, nil)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Error parsing CSV"})
+		code := http.StatusBadRequest
+		if errors.Is(err, services.ErrNoLender) {
+			code = http.StatusForbidden
+		}
+		c.JSON(code, gin.H{"error": err.Error()})
 		return
 	}
 
-	cases, err := services.CreateCasesFromCSV(env, records)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if report.Imported == 0 && report.Rejected > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "Cases rejected, see report for details",
+			"report": report,
+		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Cases uploaded successfully",
-		"count":   len(cases),
+		"count":   report.Imported,
+		"report":  report,
+	})
+}
+
+// uploadedFile returns the file of a multipart upload, capped at
+// CASE_UPLOAD_MAX_BYTES. The error response is already written when it
+// returns false.
+func uploadedFile(c *gin.Context) (*multipart.FileHeader, bool) {
+	maxBytes := viper.GetInt64("CASE_UPLOAD_MAX_BYTES")
+	if maxBytes <= 0 {
+		maxBytes = constants.DEFAULT_CASE_UPLOAD_MAX_BYTES
+	}
+	// Leave room for the multipart envelope around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+1<<20)
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("File exceeds the maximum upload size of %d bytes", maxBytes)})
+			return nil, false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		return nil, false
+	}
+	if file.Size > maxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("File exceeds the maximum upload size of %d bytes", maxBytes)})
+		return nil, false
+	}
+	return file, true
+}
+
+// startCaseImportJob keeps a copy of the upload, since the multipart temp
+// file is removed when the request ends, and hands it to a background job
+func startCaseImportJob(c *gin.Context, env *models.Env, file *multipart.FileHeader, mode string) {
+	tempFile, err := os.CreateTemp("", "case-upload-*.csv")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error storing file"})
+		return
+	}
+	tempFile.Close()
+
+	if err := c.SaveUploadedFile(file, tempFile.Name()); err != nil {
+		os.Remove(tempFile.Name())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error storing file"})
+		return
+	}
+
+	job, err := services.StartCaseImportJob(env, tempFile.Name(), file.Filename, file.Size, mode)
+	if err != nil {
+		os.Remove(tempFile.Name())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Case import started",
+		"job_id":  job.ID,
 	})
 }
 
+type ImportJobResponse struct {
+	ID         string              `json:"id"`
+	FileName   string              `json:"file_name"`
+	Mode       string              `json:"mode"`
+	Status     string              `json:"status"`
+	Progress   float64             `json:"progress"`
+	Report     models.ImportReport `json:"report"`
+	Error      string              `json:"error,omitempty"`
+	StartedAt  *time.Time          `json:"started_at"`
+	FinishedAt *time.Time          `json:"finished_at"`
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+// GetImportJobHandler reports the progress of a background case upload
+func GetImportJobHandler(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	job, err := services.GetImportJob(env, c.Param("job_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if job == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Import job not found"})
+		return
+	}
+
+	progress := 0.0
+	if job.FileSize > 0 {
+		progress = math.Round(float64(job.BytesRead)/float64(job.FileSize)*10000) / 100
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": ImportJobResponse{
+		ID:         job.ID,
+		FileName:   job.FileName,
+		Mode:       job.Mode,
+		Status:     job.Status,
+		Progress:   progress,
+		Report:     job.Report.Data(),
+		Error:      job.Error,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+		CreatedAt:  job.CreatedAt,
+	}})
+}
+
 type GetUnassignedCasesResponse struct {
 	ID                     string    `json:"id"`
 	LoanID                 string    `json:"loan_id"`
@@ -72,7 +198,13 @@ func GetUnassignedCasesHandler(c *gin.Context) {
 	val, _ := c.Get("env")
 	env := val.(*models.Env)
 
-	cases, err := services.GetUnassignedCases(env)
+	filter, err := parseCaseListQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cases, total, err := services.GetUnassignedCases(env, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -101,7 +233,7 @@ func GetUnassignedCasesHandler(c *gin.Context) {
 		})
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": response})
+	c.JSON(http.StatusOK, gin.H{"data": response, "pagination": newPagination(filter.Page, filter.PageSize, total)})
 }
 
 // AssignCasesRequest represents the request body for case assignment
@@ -123,7 +255,7 @@ func AssignCasesHandler(c *gin.Context) {
 
 	err := services.AssignCasesToAgency(env, req.AgencyID, req.CaseIDs)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(caseStatusErrorCode(err), gin.H{"error": err.Error()})
 		return
 	}
 
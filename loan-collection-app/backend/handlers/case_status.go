@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"backend/models"
+	"backend/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type UpdateCaseStatusRequest struct {
+	Status     string `json:"status" binding:"required"`
+	ReasonCode string `json:"reason_code" binding:"required"`
+	Remarks    string `json:"remarks"`
+}
+
+// POST /api/v1/cases/:caseID/status
+func UpdateCaseStatus(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	var req UpdateCaseStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	caseData, err := services.TransitionCase(env, c.Param("caseID"), req.Status, req.ReasonCode, req.Remarks)
+	if err != nil {
+		c.JSON(caseStatusErrorCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Case status updated successfully",
+		"case_id":     caseData.ID,
+		"case_status": caseData.CaseStatus,
+	})
+}
+
+// GET /api/v1/cases/:caseID/status-history
+func GetCaseStatusHistory(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	history, err := services.GetCaseStatusHistory(env, c.Param("caseID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": history})
+}
+
+// caseStatusErrorCode maps lifecycle errors to a response status
+func caseStatusErrorCode(err error) int {
+	var transitionErr *services.CaseTransitionError
+	switch {
+	case errors.Is(err, services.ErrInvalidStatusChange):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrStatusNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.As(err, &transitionErr):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
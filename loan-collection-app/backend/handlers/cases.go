@@ -9,30 +9,6 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// In-memory data
-type Case struct {
-	CaseID        int     `json:"case_id"`
-	UserName      string  `json:"user_name"`
-	LoanID        string  `json:"loan_id"`
-	LoanAmount    float64 `json:"loan_amount"`
-	EMIMonthly    float64 `json:"emi_monthly"`
-	DaysPastDue   int     `json:"days_past_due"`
-	CustomerAddr  string  `json:"customer_addr"`
-	CustomerPhone string  `json:"customer_phone"`
-	// ... add other fields as needed
-}
-
-var cases = []Case{
-	{
-		CaseID: 1, UserName: "John Doe", LoanID: "LN123456", LoanAmount: 15000, EMIMonthly: 1500, DaysPastDue: 10,
-		CustomerAddr: "123 Main St, Cityville", CustomerPhone: "9876543210",
-	},
-	{
-		CaseID: 2, UserName: "Jane Smith", LoanID: "LN789012", LoanAmount: 20000, EMIMonthly: 2000, DaysPastDue: 5,
-		CustomerAddr: "456 Elm St, Townsville", CustomerPhone: "9898989898",
-	},
-}
-
 // GET /api/cases
 type GetAgentCasesResponse struct {
 	ID                     string    `json:"id"`
@@ -58,7 +34,24 @@ func GetAgentCases(c *gin.Context) {
 	val, _ := c.Get("env")
 	env := val.(*models.Env)
 
-	cases, err := services.GetAssignedCases(env, env.AuthDtos.User.ID)
+	filter, err := parseCaseListQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	format, err := exportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if format != "" {
+		writeExport(c, format, "cases", caseExportColumns, exportCaseBatch(filter, func(filter models.CaseFilter) ([]models.Case, int64, error) {
+			return services.GetAssignedCases(env, env.AuthDtos.User.ID, filter)
+		}))
+		return
+	}
+
+	cases, total, err := services.GetAssignedCases(env, env.AuthDtos.User.ID, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -85,29 +78,34 @@ func GetAgentCases(c *gin.Context) {
 			NachPresentationStatus: caseData.NachPresentationStatus,
 		})
 	}
-	c.JSON(http.StatusOK, gin.H{"data": response})
+	c.JSON(http.StatusOK, gin.H{"data": response, "pagination": newPagination(filter.Page, filter.PageSize, total)})
 }
 
 type GetCaseDetailsResponse struct {
-	CaseID                 string    `json:"case_id"`
-	AgentName              string    `json:"agent_name"`
-	LoanID                 string    `json:"loan_id"`
-	LoanAmount             float64   `json:"loan_amount"`
-	EMIMonthly             float64   `json:"emi_monthly"`
-	DaysPastDue            int       `json:"days_past_due"`
-	CustomerAddr           string    `json:"customer_addr"`
-	CustomerPhone          string    `json:"customer_phone"`
-	CaseStatus             string    `json:"case_status"`
-	EMIDate                time.Time `json:"emi_date"`
-	DPDBucket              string    `json:"dpd_bucket"`
-	DPD                    int       `json:"dpd"`
-	DisbursalDate          time.Time `json:"disbursal_date"`
-	InsuranceActive        bool      `json:"insurance_active"`
-	LoanDescription        string    `json:"loan_description"`
-	EMIsPaidTillDate       int       `json:"emis_paid_till_date"`
-	EMIsPending            int       `json:"emis_pending"`
-	BounceCharges          float64   `json:"bounce_charges"`
-	NachPresentationStatus string    `json:"nach_presentation_status"`
+	CaseID                 string                   `json:"case_id"`
+	AgentName              string                   `json:"agent_name"`
+	LoanID                 string                   `json:"loan_id"`
+	LoanAmount             float64                  `json:"loan_amount"`
+	EMIMonthly             float64                  `json:"emi_monthly"`
+	DaysPastDue            int                      `json:"days_past_due"`
+	CustomerName           string                   `json:"customer_name"`
+	CustomerAddr           string                   `json:"customer_addr"`
+	CustomerPhone          string                   `json:"customer_phone"`
+	CustomerPhones         []string                 `json:"customer_phones"`
+	CustomerAddresses      []models.CustomerAddress `json:"customer_addresses"`
+	CustomerEmail          *string                  `json:"customer_email"`
+	CustomerPincode        string                   `json:"customer_pincode"`
+	CaseStatus             string                   `json:"case_status"`
+	EMIDate                time.Time                `json:"emi_date"`
+	DPDBucket              string                   `json:"dpd_bucket"`
+	DPD                    int                      `json:"dpd"`
+	DisbursalDate          time.Time                `json:"disbursal_date"`
+	InsuranceActive        bool                     `json:"insurance_active"`
+	LoanDescription        string                   `json:"loan_description"`
+	EMIsPaidTillDate       int                      `json:"emis_paid_till_date"`
+	EMIsPending            int                      `json:"emis_pending"`
+	BounceCharges          float64                  `json:"bounce_charges"`
+	NachPresentationStatus string                   `json:"nach_presentation_status"`
 }
 
 // GET /api/cases/:caseID
@@ -117,20 +115,17 @@ func GetCaseDetails(c *gin.Context) {
 
 	caseIDStr := c.Param("caseID")
 
-	caseData, userData, err := services.GetCaseDetails(env, caseIDStr)
+	caseData, userData, customer, err := services.GetCaseDetails(env, caseIDStr)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	response := GetCaseDetailsResponse{
 		CaseID:                 caseData.ID,
-		AgentName:              userData.Username,
 		LoanID:                 caseData.LoanID,
 		LoanAmount:             caseData.PrincipalOutstanding,
 		EMIMonthly:             caseData.EMIAmount,
 		DaysPastDue:            caseData.DPD,
-		CustomerAddr:           "123 Main St, Cityville",
-		CustomerPhone:          "9876543210",
 		CaseStatus:             caseData.CaseStatus,
 		EMIDate:                caseData.EMIDate,
 		DPDBucket:              caseData.DPDBucket,
@@ -142,6 +137,24 @@ func GetCaseDetails(c *gin.Context) {
 		EMIsPending:            caseData.EMIsPending,
 		BounceCharges:          caseData.BounceCharges,
 		NachPresentationStatus: caseData.NachPresentationStatus,
+		CustomerPhones:         []string{},
+		CustomerAddresses:      []models.CustomerAddress{},
+	}
+	if userData != nil {
+		response.AgentName = userData.Username
+	}
+	if customer != nil {
+		response.CustomerName = customer.Name
+		response.CustomerEmail = customer.Email
+		response.CustomerPincode = customer.Pincode
+		if len(customer.Phones) > 0 {
+			response.CustomerPhone = customer.Phones[0]
+			response.CustomerPhones = customer.Phones
+		}
+		if len(customer.Addresses) > 0 {
+			response.CustomerAddr = customer.Addresses[0].Address
+			response.CustomerAddresses = customer.Addresses
+		}
 	}
 	c.JSON(http.StatusOK, gin.H{"data": response})
 }
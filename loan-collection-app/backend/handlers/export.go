@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"backend/constants"
+	"backend/models"
+	"backend/utils"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportColumn is one column of a listing export. The value is written as a
+// number, boolean or text depending on its type.
+type exportColumn[T any] struct {
+	name  string
+	value func(T) interface{}
+}
+
+// exportColumnsOf reuses the columns of U for rows of T
+func exportColumnsOf[T, U any](columns []exportColumn[U], get func(T) U) []exportColumn[T] {
+	result := make([]exportColumn[T], 0, len(columns))
+	for _, column := range columns {
+		result = append(result, exportColumn[T]{
+			name:  column.name,
+			value: func(row T) interface{} { return column.value(get(row)) },
+		})
+	}
+	return result
+}
+
+type exportWriter interface {
+	WriteRow(cells []interface{}) error
+	Flush() error
+	Close() error
+}
+
+type csvExportWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvExportWriter) WriteRow(cells []interface{}) error {
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		if cell != nil {
+			record[i] = fmt.Sprint(exportCell(cell))
+		}
+	}
+	return w.writer.Write(record)
+}
+
+func (w *csvExportWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+func (w *csvExportWriter) Close() error {
+	return w.Flush()
+}
+
+// exportFormat returns the format a listing was requested in, or "" for
+// JSON. The format query parameter takes precedence over the Accept header.
+func exportFormat(c *gin.Context) (string, error) {
+	switch format := strings.ToLower(c.Query("format")); format {
+	case "":
+	case "json":
+		return "", nil
+	case constants.EXPORT_FORMAT_CSV, constants.EXPORT_FORMAT_XLSX:
+		return format, nil
+	default:
+		return "", fmt.Errorf("format must be json, %s or %s", constants.EXPORT_FORMAT_CSV, constants.EXPORT_FORMAT_XLSX)
+	}
+
+	accept := c.GetHeader("Accept")
+	switch {
+	case strings.Contains(accept, constants.CSV_CONTENT_TYPE):
+		return constants.EXPORT_FORMAT_CSV, nil
+	case strings.Contains(accept, constants.XLSX_CONTENT_TYPE):
+		return constants.EXPORT_FORMAT_XLSX, nil
+	}
+	return "", nil
+}
+
+// selectExportColumns narrows the columns to the comma separated names in
+// the columns query parameter, in the order given. All columns are exported
+// when it is empty.
+func selectExportColumns[T any](c *gin.Context, columns []exportColumn[T]) ([]exportColumn[T], error) {
+	names := splitQueryValues(c.QueryArray("columns"))
+	if len(names) == 0 {
+		return columns, nil
+	}
+
+	selected := make([]exportColumn[T], 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, column := range columns {
+			if column.name == name {
+				selected = append(selected, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column %s", name)
+		}
+	}
+	return selected, nil
+}
+
+// writeExport streams a listing as CSV or XLSX. batch returns the rows of
+// one zero based batch of EXPORT_BATCH_SIZE rows; the export ends at the
+// first short batch. Errors before the first row is written are returned as
+// JSON, later ones can only cut the download short.
+func writeExport[T any](c *gin.Context, format, name string, columns []exportColumn[T], batch func(index int) ([]T, error)) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	// Exports are served by the listing routes themselves, picked by format,
+	// so the route's PermissionMiddleware only covers the JSON listing and
+	// the extra export permission has to be checked here
+	if !utils.HasPermission(env, "export_cases") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions. Required: export_cases"})
+		return
+	}
+	columns, err := selectExportColumns(c, columns)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rows, err := batch(0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102"), format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	var writer exportWriter
+	if format == constants.EXPORT_FORMAT_XLSX {
+		c.Header("Content-Type", constants.XLSX_CONTENT_TYPE)
+		if writer, err = utils.NewXLSXWriter(c.Writer, name); err != nil {
+			env.Logger.Error(err.Error())
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+	} else {
+		c.Header("Content-Type", constants.CSV_CONTENT_TYPE+"; charset=utf-8")
+		writer = &csvExportWriter{writer: csv.NewWriter(c.Writer)}
+	}
+	c.Status(http.StatusOK)
+
+	header := make([]interface{}, len(columns))
+	for i, column := range columns {
+		header[i] = column.name
+	}
+	if err := writer.WriteRow(header); err != nil {
+		env.Logger.Error(err.Error())
+		return
+	}
+
+	for index := 0; ; index++ {
+		if index > 0 {
+			if rows, err = batch(index); err != nil {
+				env.Logger.Error(err.Error())
+				return
+			}
+		}
+		for _, row := range rows {
+			cells := make([]interface{}, len(columns))
+			for i, column := range columns {
+				cells[i] = column.value(row)
+			}
+			if err := writer.WriteRow(cells); err != nil {
+				env.Logger.Error(err.Error())
+				return
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			env.Logger.Error(err.Error())
+			return
+		}
+		c.Writer.Flush()
+		if len(rows) < constants.EXPORT_BATCH_SIZE {
+			break
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		env.Logger.Error(err.Error())
+	}
+}
+
+// exportCell guards CSV text cells against formula injection. Spreadsheet
+// apps evaluate CSV text starting with =, +, -, @, tab or carriage return, so
+// such values are prefixed with a quote and shown as typed. Numbers and
+// booleans are written as they are. XLSX text cells are never evaluated and
+// need no guard.
+func exportCell(value interface{}) interface{} {
+	switch value.(type) {
+	case nil, int, int64, float64, bool:
+		return value
+	}
+	text := fmt.Sprint(value)
+	if text != "" && strings.ContainsRune(constants.EXPORT_FORMULA_PREFIXES, rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+// exportAll serves an unpaged listing as a single batch
+func exportAll[T any](list func() ([]T, error)) func(index int) ([]T, error) {
+	return func(index int) ([]T, error) {
+		if index > 0 {
+			return nil, nil
+		}
+		return list()
+	}
+}
+
+// exportCaseBatch pages through the cases matching filter
+func exportCaseBatch[T any](filter models.CaseFilter, list func(models.CaseFilter) ([]T, int64, error)) func(index int) ([]T, error) {
+	return func(index int) ([]T, error) {
+		filter.Page = index + 1
+		filter.PageSize = constants.EXPORT_BATCH_SIZE
+		rows, _, err := list(filter)
+		return rows, err
+	}
+}
+
+func exportDate(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.Format(time.DateOnly)
+}
+
+func exportString(value *string) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+var caseExportColumns = []exportColumn[models.Case]{
+	{"id", func(c models.Case) interface{} { return c.ID }},
+	{"loan_id", func(c models.Case) interface{} { return c.LoanID }},
+	{"external_customer_id", func(c models.Case) interface{} { return c.ExternalCustomerID }},
+	{"emi_amount", func(c models.Case) interface{} { return c.EMIAmount }},
+	{"principal_outstanding", func(c models.Case) interface{} { return c.PrincipalOutstanding }},
+	{"interest_outstanding", func(c models.Case) interface{} { return c.InterestOutstanding }},
+	{"case_status", func(c models.Case) interface{} { return c.CaseStatus }},
+	{"emi_date", func(c models.Case) interface{} { return exportDate(c.EMIDate) }},
+	{"dpd_bucket", func(c models.Case) interface{} { return c.DPDBucket }},
+	{"dpd", func(c models.Case) interface{} { return c.DPD }},
+	{"disbursal_date", func(c models.Case) interface{} { return exportDate(c.DisbursalDate) }},
+	{"insurance_active", func(c models.Case) interface{} { return c.InsuranceActive }},
+	{"loan_description", func(c models.Case) interface{} { return c.LoanDescription }},
+	{"emis_paid_till_date", func(c models.Case) interface{} { return c.EMIsPaidTillDate }},
+	{"emis_pending", func(c models.Case) interface{} { return c.EMIsPending }},
+	{"bounce_charges", func(c models.Case) interface{} { return c.BounceCharges }},
+	{"nach_presentation_status", func(c models.Case) interface{} { return c.NachPresentationStatus }},
+}
+
+var agencyCaseExportColumns = append(
+	exportColumnsOf(caseExportColumns, func(c models.CaseWithAssignee) models.Case { return c.Case }),
+	exportColumn[models.CaseWithAssignee]{"assigned_to_id", func(c models.CaseWithAssignee) interface{} { return exportString(c.AssigneeID) }},
+	exportColumn[models.CaseWithAssignee]{"assigned_to_username", func(c models.CaseWithAssignee) interface{} { return exportString(c.AssigneeUsername) }},
+	exportColumn[models.CaseWithAssignee]{"assigned_to_email", func(c models.CaseWithAssignee) interface{} { return exportString(c.AssigneeEmail) }},
+)
+
+var userExportColumns = []exportColumn[models.UserWithRoles]{
+	{"id", func(u models.UserWithRoles) interface{} { return u.ID }},
+	{"username", func(u models.UserWithRoles) interface{} { return u.Username }},
+	{"email", func(u models.UserWithRoles) interface{} { return exportString(u.Email) }},
+	{"is_active", func(u models.UserWithRoles) interface{} { return u.IsActive }},
+	{"role_list", func(u models.UserWithRoles) interface{} { return strings.Join(u.RoleNames, ", ") }},
+}
+
+var agencyUserExportColumns = []exportColumn[models.AgencyUserDetails]{
+	{"id", func(u models.AgencyUserDetails) interface{} { return u.UserID }},
+	{"username", func(u models.AgencyUserDetails) interface{} { return u.Username }},
+	{"email", func(u models.AgencyUserDetails) interface{} { return u.Email }},
+	{"role", func(u models.AgencyUserDetails) interface{} { return u.AgencyRole }},
+	{"manager_id", func(u models.AgencyUserDetails) interface{} { return exportString(u.ManagerID) }},
+	{"max_open_cases", func(u models.AgencyUserDetails) interface{} {
+		if u.MaxOpenCases == nil {
+			return nil
+		}
+		return *u.MaxOpenCases
+	}},
+	{"territories", func(u models.AgencyUserDetails) interface{} { return strings.Join(u.Territories, ", ") }},
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"archive/zip"
+	"backend/constants"
+	"backend/models"
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestExportCell(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+	}{
+		{"nil", nil, nil},
+		{"negative number", -12.5, -12.5},
+		{"integer", 7, 7},
+		{"boolean", true, true},
+		{"plain text", "Asha", "Asha"},
+		{"empty text", "", ""},
+		{"date", "2026-01-05", "2026-01-05"},
+		{"formula", "=1+1", "'=1+1"},
+		{"plus", "+91 98450", "'+91 98450"},
+		{"minus", "-2+3", "'-2+3"},
+		{"at", "@SUM(A1)", "'@SUM(A1)"},
+		{"tab", "\t=1", "'\t=1"},
+		{"carriage return", "\r=1", "'\r=1"},
+		{"formula inside text", "a=1", "a=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exportCell(tt.value); got != tt.want {
+				t.Errorf("exportCell(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteExportGuardsOnlyCSV(t *testing.T) {
+	columns := []exportColumn[string]{{name: "phone", value: func(row string) interface{} { return row }}}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{constants.EXPORT_FORMAT_CSV, "phone\n'+91 98450\n"},
+		{constants.EXPORT_FORMAT_XLSX, `<t xml:space="preserve">+91 98450</t>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest("GET", "/cases", nil)
+			c.Set("env", &models.Env{PermissionList: []string{"export_cases"}, Logger: zap.NewNop()})
+
+			writeExport(c, tt.format, "cases", columns, exportAll(func() ([]string, error) {
+				return []string{"+91 98450"}, nil
+			}))
+
+			body := recorder.Body.String()
+			if tt.format == constants.EXPORT_FORMAT_XLSX {
+				body = xlsxSheet(t, recorder.Body.Bytes())
+			}
+			if !strings.Contains(body, tt.want) {
+				t.Errorf("%s export = %q, want it to contain %q", tt.format, body, tt.want)
+			}
+		})
+	}
+}
+
+// xlsxSheet returns the sheet XML of a workbook written by utils.XLSXWriter
+func xlsxSheet(t *testing.T, workbook []byte) string {
+	t.Helper()
+	archive, err := zip.NewReader(bytes.NewReader(workbook), int64(len(workbook)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := archive.Open("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	sheet, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(sheet)
+}
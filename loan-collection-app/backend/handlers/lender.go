@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"backend/models"
+	"backend/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LenderResponse struct {
+	Id         string `json:"id"`
+	LenderName string `json:"lender_name"`
+	Status     string `json:"status"`
+}
+
+func ListAllLenders(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	lenders, err := services.ListAllLenders(env)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := []LenderResponse{}
+	for _, lender := range lenders {
+		response = append(response, LenderResponse{
+			Id:         lender.ID,
+			LenderName: lender.LenderName,
+			Status:     lender.Status,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type CreateLenderRequest struct {
+	LenderName string `json:"lender_name" binding:"required"`
+}
+
+func CreateLender(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	var req CreateLenderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lender := models.Lender{LenderName: req.LenderName}
+	if err := services.CreateLender(env, &lender); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, LenderResponse{
+		Id:         lender.ID,
+		LenderName: lender.LenderName,
+		Status:     lender.Status,
+	})
+}
+
+type AssignUserToLenderRequest struct {
+	UserID   string `json:"user_id" binding:"required"`
+	LenderID string `json:"lender_id" binding:"required"`
+}
+
+func AssignUserToLender(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	var req AssignUserToLenderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	mapping := models.LenderUserMap{
+		UserID:   req.UserID,
+		LenderID: req.LenderID,
+		IsActive: true,
+	}
+	if err := services.AssignUserToLender(env, &mapping); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "User assigned to lender successfully"})
+}
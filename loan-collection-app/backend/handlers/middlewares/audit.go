@@ -0,0 +1,71 @@
+package middlewares
+
+import (
+	"backend/constants"
+	"backend/models"
+	"backend/services"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AuditMiddleware records every POST, PUT, PATCH and DELETE in the audit log
+// once the handler has finished, whatever its outcome. Services add the
+// entity and its before and after state through services.AuditEntity and
+// services.AuditChange.
+func AuditMiddleware(c *gin.Context) {
+	switch c.Request.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		c.Next()
+		return
+	}
+
+	val, exists := c.Get("env")
+	if !exists {
+		c.Next()
+		return
+	}
+	env := val.(*models.Env)
+
+	requestID := c.GetHeader("X-Request-ID")
+	if requestID == "" || len(requestID) > constants.MAX_REQUEST_ID_LENGTH {
+		requestID = uuid.NewString()
+	}
+	c.Header("X-Request-ID", requestID)
+
+	event := &models.AuditEvent{
+		Action:    c.Request.Method + " " + c.FullPath(),
+		IPAddress: c.ClientIP(),
+		RequestID: requestID,
+	}
+	event.EntityType, event.EntityID = auditPathEntity(c)
+	env.RequestContext[constants.AUDIT_EVENT_CONTEXT_KEY] = event
+
+	c.Next()
+
+	event.StatusCode = c.Writer.Status()
+	if err := services.RecordAuditEvent(env, event); err != nil {
+		env.Logger.Error("Error recording audit event: " + err.Error())
+	}
+}
+
+// auditPathEntity guesses the entity of a request from its route: the type
+// from the first segment after the API version and the ID from the first
+// path parameter
+func auditPathEntity(c *gin.Context) (string, string) {
+	path := strings.TrimPrefix(c.FullPath(), "/api/v1/")
+	segment, _, _ := strings.Cut(path, "/")
+	entityType, ok := constants.AuditPathEntityTypes[segment]
+	if !ok {
+		entityType = segment
+	}
+
+	entityID := ""
+	if len(c.Params) > 0 {
+		entityID = c.Params[0].Value
+	}
+	return entityType, entityID
+}
@@ -7,15 +7,19 @@ import (
 	"backend/services"
 	"backend/utils"
 	"fmt"
+	"maps"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/spf13/viper"
 )
 
-// AuthMiddleware validates JWT token and attaches user information to the context
+// AuthMiddleware validates JWT token and attaches user information to the context.
+// It also applies the per-user rate limit of the route.
 func AuthMiddleware(c *gin.Context) {
 	// Get environment from context
 	val, exists := c.Get("env")
@@ -72,24 +76,53 @@ func AuthMiddleware(c *gin.Context) {
 			return
 		}
 
+		jti, ok := claims["jti"].(string)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			return
+		}
+		issuedAt, err := claims.GetIssuedAt()
+		if err != nil || issuedAt == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			return
+		}
+		expiresAt, err := claims.GetExpirationTime()
+		if err != nil || expiresAt == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			return
+		}
+
+		// Reject tokens revoked by a logout
+		revoked, err := services.IsAccessTokenRevoked(env, userID, jti, issuedAt.Time)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if revoked {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			return
+		}
+
 		// Find user in database to ensure they exist and are active
 		userRepo := repository.NewUserRepository(env.DbConn)
 		user, err := userRepo.FindByID(userID)
-		if err != nil || user == nil {
+		if err != nil || user == nil || !user.IsActive {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 			return
 		}
+		env.RequestContext["token_id"] = jti
+		env.RequestContext["token_expires_at"] = expiresAt.Time
 		env.AuthDtos.User = user
-		roleList, err := services.GetRolesByUser(env, user.ID)
+
+		if !allowRequest(c, env, "user:"+user.ID) {
+			return
+		}
+
+		userPermissionList, err := services.GetPermissionsByUser(env, user.ID)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		userPermissionList := make([]string, 0)
-		for _, role := range roleList {
-			permissionList := constants.RolePermissionsMap[role.RoleName]
-			userPermissionList = append(userPermissionList, permissionList...)
-		}
 		env.PermissionList = userPermissionList
 
 		c.Next()
@@ -99,8 +132,24 @@ func AuthMiddleware(c *gin.Context) {
 	}
 }
 
+var (
+	registeredPermissions   = map[string]struct{}{}
+	registeredPermissionsMu sync.Mutex
+)
+
+// RegisteredPermissions lists every permission checked by a route, sorted
+func RegisteredPermissions() []string {
+	registeredPermissionsMu.Lock()
+	defer registeredPermissionsMu.Unlock()
+	return slices.Sorted(maps.Keys(registeredPermissions))
+}
+
 // PermissionMiddleware checks if the authenticated user has the required permission
 func PermissionMiddleware(requiredPermission string) gin.HandlerFunc {
+	registeredPermissionsMu.Lock()
+	registeredPermissions[requiredPermission] = struct{}{}
+	registeredPermissionsMu.Unlock()
+
 	return func(c *gin.Context) {
 		// Get environment from context
 		val, exists := c.Get("env")
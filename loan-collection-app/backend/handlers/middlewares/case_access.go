@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"backend/models"
+	"backend/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CaseAccessMiddleware lets a request through only when the :caseID it
+// targets is within the caller's scope. Other cases answer 404 so their
+// existence is not revealed.
+func CaseAccessMiddleware(c *gin.Context) {
+	val, exists := c.Get("env")
+	if !exists {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Environment not configured"})
+		return
+	}
+	env := val.(*models.Env)
+
+	allowed, err := services.CanAccessCase(env, c.Param("caseID"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !allowed {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Case not found"})
+		return
+	}
+
+	c.Next()
+}
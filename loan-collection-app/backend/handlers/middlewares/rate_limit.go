@@ -0,0 +1,130 @@
+package middlewares
+
+import (
+	"backend/constants"
+	"backend/models"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis_rate/v10"
+	"github.com/spf13/viper"
+)
+
+// RateLimitRule is one entry of RATE_LIMIT_ROUTES, matched against the
+// method and path pattern of a route, e.g. "POST /api/v1/login"
+type RateLimitRule struct {
+	Route  string        `mapstructure:"route"`
+	Rate   int           `mapstructure:"rate"`
+	Burst  int           `mapstructure:"burst"`
+	Period time.Duration `mapstructure:"period"`
+}
+
+func (r RateLimitRule) limit() redis_rate.Limit {
+	limit := redis_rate.Limit{Rate: r.Rate, Burst: r.Burst, Period: r.Period}
+	if limit.Period <= 0 {
+		limit.Period = time.Minute
+	}
+	if limit.Burst <= 0 {
+		limit.Burst = limit.Rate
+	}
+	return limit
+}
+
+var (
+	rateLimitRules     map[string]redis_rate.Limit
+	defaultRateLimit   redis_rate.Limit
+	rateLimitRulesOnce sync.Once
+)
+
+func loadRateLimitRules() {
+	defaultRule := RateLimitRule{
+		Rate:   constants.DEFAULT_RATE_LIMIT_PER_MINUTE,
+		Period: time.Minute,
+	}
+	if viper.IsSet("RATE_LIMIT_DEFAULT") {
+		if err := viper.UnmarshalKey("RATE_LIMIT_DEFAULT", &defaultRule); err != nil {
+			fmt.Println("Error reading RATE_LIMIT_DEFAULT:", err)
+		}
+	}
+	defaultRateLimit = defaultRule.limit()
+
+	var rules []RateLimitRule
+	if err := viper.UnmarshalKey("RATE_LIMIT_ROUTES", &rules); err != nil {
+		fmt.Println("Error reading RATE_LIMIT_ROUTES:", err)
+	}
+	rateLimitRules = make(map[string]redis_rate.Limit, len(rules))
+	for _, rule := range rules {
+		rateLimitRules[strings.TrimSpace(rule.Route)] = rule.limit()
+	}
+}
+
+// routeRateLimit returns the configured limit for the matched route, or the
+// default limit
+func routeRateLimit(route string) redis_rate.Limit {
+	rateLimitRulesOnce.Do(loadRateLimitRules)
+	if limit, ok := rateLimitRules[route]; ok {
+		return limit
+	}
+	return defaultRateLimit
+}
+
+// IPRateLimitMiddleware limits unauthenticated routes, such as login, per client IP
+func IPRateLimitMiddleware(c *gin.Context) {
+	val, exists := c.Get("env")
+	if !exists {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Environment not configured"})
+		return
+	}
+	env := val.(*models.Env)
+
+	if !allowRequest(c, env, "ip:"+c.ClientIP()) {
+		return
+	}
+	c.Next()
+}
+
+// allowRequest counts the request against the route limit of subject and sets
+// the RateLimit headers. It aborts with 429 and returns false once the limit is
+// used up. Limiter errors let the request through, so an unavailable Redis does
+// not take the API down.
+func allowRequest(c *gin.Context, env *models.Env, subject string) bool {
+	if env.RateLimiter == nil {
+		return true
+	}
+
+	route := c.Request.Method + " " + c.FullPath()
+	limit := routeRateLimit(route)
+	if limit.Rate <= 0 {
+		return true
+	}
+
+	result, err := env.RateLimiter.Allow(c, fmt.Sprintf("rate_limit:%s:%s", route, subject), limit)
+	if err != nil {
+		env.Logger.Error(err.Error())
+		return true
+	}
+
+	c.Header("RateLimit-Limit", strconv.Itoa(limit.Rate))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+	if result.Allowed == 0 {
+		c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, constants.RESPONSE_TOO_MANY_REQUESTS)
+		return false
+	}
+	return true
+}
+
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
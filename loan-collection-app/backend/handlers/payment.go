@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"backend/models"
+	"backend/payments"
+	"backend/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreatePaymentLinkRequest struct {
+	AmountType string  `json:"amount_type" binding:"required"`
+	Amount     float64 `json:"amount"`
+}
+
+type PaymentLinkResponse struct {
+	ID         string    `json:"id"`
+	CaseID     string    `json:"case_id"`
+	AmountType string    `json:"amount_type"`
+	Amount     float64   `json:"amount"`
+	Provider   string    `json:"provider"`
+	URL        string    `json:"payment_link"`
+	Status     string    `json:"status"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// POST /api/cases/:caseID/payment-link
+func CreatePaymentLink(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	var req CreatePaymentLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	link, err := services.CreatePaymentLink(env, c.Param("caseID"), req.AmountType, req.Amount)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, newPaymentLinkResponse(*link))
+}
+
+// GET /api/cases/:caseID/payment-links
+func ListPaymentLinks(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	links, err := services.ListPaymentLinks(env, c.Param("caseID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := []PaymentLinkResponse{}
+	for _, link := range links {
+		response = append(response, newPaymentLinkResponse(link))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+func newPaymentLinkResponse(link models.PaymentLink) PaymentLinkResponse {
+	return PaymentLinkResponse{
+		ID:         link.ID,
+		CaseID:     link.CaseID,
+		AmountType: link.AmountType,
+		Amount:     link.Amount,
+		Provider:   link.Provider,
+		URL:        link.URL,
+		Status:     link.Status,
+		ExpiresAt:  link.ExpiresAt,
+		CreatedAt:  link.CreatedAt,
+	}
+}
+
+// POST /api/v1/payments/webhook
+// Called by the payment gateway, so it is authenticated by signature instead of a token
+func PaymentWebhookHandler(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	provider, err := payments.NewProvider(env.HttpClient)
+	if err != nil {
+		env.Logger.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	event, err := provider.ParseWebhook(c.Request.Header, body)
+	if err != nil {
+		env.Logger.Error(err.Error())
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid webhook"})
+		return
+	}
+
+	if err := services.HandlePaymentWebhook(env, provider.Name(), event); err != nil {
+		env.Logger.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook processed"})
+}
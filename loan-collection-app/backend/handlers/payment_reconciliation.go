@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"backend/constants"
+	"backend/models"
+	"backend/services"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// POST /api/v1/payments/reconciliations
+func ReconcilePaymentsHandler(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	file, ok := uploadedFile(c)
+	if !ok {
+		return
+	}
+	openedFile, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error opening file"})
+		return
+	}
+	defer openedFile.Close()
+
+	reconciliation, err := services.ReconcilePayments(env, csv.NewReader(openedFile), file.Filename)
+	if err != nil {
+		c.JSON(reconciliationErrorCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Payment file reconciled",
+		"data":    reconciliation,
+	})
+}
+
+type ReconciliationListQuery struct {
+	Page     int `form:"page"`
+	PageSize int `form:"page_size"`
+}
+
+// GET /api/v1/payments/reconciliations
+func ListPaymentReconciliations(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	var query ReconciliationListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	page, pageSize, err := reconciliationPage(query.Page, query.PageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reconciliations, total, err := services.ListPaymentReconciliations(env, page, pageSize)
+	if err != nil {
+		c.JSON(reconciliationErrorCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": reconciliations, "pagination": newPagination(page, pageSize, total)})
+}
+
+type ReconciliationLineQuery struct {
+	Page     int    `form:"page"`
+	PageSize int    `form:"page_size"`
+	Status   string `form:"status"`
+}
+
+// GET /api/v1/payments/reconciliations/:reconciliation_id
+// Returns the summary and a page of the lines, or every line as CSV or XLSX
+// when an export format is requested.
+func GetPaymentReconciliation(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	var query ReconciliationLineQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter := models.ReconciliationLineFilter{Status: optionalString(strings.ToUpper(query.Status))}
+	if filter.Status != nil && !slices.Contains(constants.ReconciliationLineStatuses, *filter.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("status must be one of %s", strings.Join(constants.ReconciliationLineStatuses, ", "))})
+		return
+	}
+	var err error
+	if filter.Page, filter.PageSize, err = reconciliationPage(query.Page, query.PageSize); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	format, err := exportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reconciliationID := c.Param("reconciliation_id")
+	reconciliation, lines, total, err := services.GetPaymentReconciliation(env, reconciliationID, filter)
+	if err != nil {
+		c.JSON(reconciliationErrorCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	if reconciliation == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reconciliation not found"})
+		return
+	}
+
+	if format != "" {
+		writeExport(c, format, "reconciliation", reconciliationLineExportColumns, func(index int) ([]models.PaymentReconciliationLine, error) {
+			filter.Page = index + 1
+			filter.PageSize = constants.EXPORT_BATCH_SIZE
+			_, lines, _, err := services.GetPaymentReconciliation(env, reconciliationID, filter)
+			return lines, err
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       reconciliation,
+		"lines":      lines,
+		"pagination": newPagination(filter.Page, filter.PageSize, total),
+	})
+}
+
+func reconciliationPage(page, pageSize int) (int, int, error) {
+	if pageSize <= 0 {
+		pageSize = constants.DEFAULT_RECONCILIATION_PAGE_SIZE
+	}
+	if pageSize > constants.MAX_RECONCILIATION_PAGE_SIZE {
+		return 0, 0, fmt.Errorf("page_size cannot exceed %d", constants.MAX_RECONCILIATION_PAGE_SIZE)
+	}
+	return max(page, 1), pageSize, nil
+}
+
+// reconciliationErrorCode maps reconciliation errors to a response status
+func reconciliationErrorCode(err error) int {
+	switch {
+	case errors.Is(err, services.ErrNoLender):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrInvalidPaymentFile):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+var reconciliationLineExportColumns = []exportColumn[models.PaymentReconciliationLine]{
+	{"row", func(l models.PaymentReconciliationLine) interface{} { return l.Row }},
+	{"loan_id", func(l models.PaymentReconciliationLine) interface{} { return l.LoanID }},
+	{"amount", func(l models.PaymentReconciliationLine) interface{} { return l.Amount }},
+	{"paid_at", func(l models.PaymentReconciliationLine) interface{} {
+		if l.PaidAt == nil {
+			return nil
+		}
+		return exportDate(*l.PaidAt)
+	}},
+	{"mode", func(l models.PaymentReconciliationLine) interface{} { return l.Mode }},
+	{"reference", func(l models.PaymentReconciliationLine) interface{} { return l.Reference }},
+	{"status", func(l models.PaymentReconciliationLine) interface{} { return l.Status }},
+	{"reason", func(l models.PaymentReconciliationLine) interface{} { return l.Reason }},
+	{"case_id", func(l models.PaymentReconciliationLine) interface{} { return exportString(l.CaseID) }},
+	{"payment_id", func(l models.PaymentReconciliationLine) interface{} { return exportString(l.PaymentID) }},
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"backend/constants"
+	"backend/models"
+	"backend/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CollectionPerformanceQuery holds the query parameters of the collection
+// performance report. from and to are YYYY-MM-DD dates and to includes the
+// whole day.
+type CollectionPerformanceQuery struct {
+	From     string `form:"from" binding:"required"`
+	To       string `form:"to" binding:"required"`
+	GroupBy  string `form:"group_by"`
+	AgencyID string `form:"agency_id"`
+}
+
+// GET /api/v1/reports/collection-performance
+func GetCollectionPerformance(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	var query CollectionPerformanceQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	from, err := parseOptionalDate(query.From)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+		return
+	}
+	to, err := parseOptionalDate(query.To)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+		return
+	}
+	if query.AgencyID != "" && !isUUID(query.AgencyID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid agency_id"})
+		return
+	}
+	if query.GroupBy == "" {
+		query.GroupBy = constants.REPORT_GROUP_AGENCY
+	}
+
+	rows, err := services.GetCollectionPerformance(env, models.CollectionPerformanceFilter{
+		From:     *from,
+		To:       to.AddDate(0, 0, 1),
+		GroupBy:  query.GroupBy,
+		AgencyID: optionalString(query.AgencyID),
+	})
+	if err != nil {
+		c.JSON(reportErrorCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":     query.From,
+		"to":       query.To,
+		"group_by": query.GroupBy,
+		"data":     rows,
+	})
+}
+
+// reportErrorCode maps report errors to a response status
+func reportErrorCode(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidReport):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrNoReportScope):
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
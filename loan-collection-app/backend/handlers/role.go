@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"backend/handlers/middlewares"
 	"backend/models"
 	"backend/services"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type CreateRoleRequest struct {
@@ -56,6 +59,10 @@ func UpdateRole(c *gin.Context) {
 	}
 
 	if err := services.UpdateRole(env, roleID, roleInput.RoleName, roleInput.Description); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -75,6 +82,10 @@ func DeleteRole(c *gin.Context) {
 	}
 
 	if err := services.DeleteRole(env, roleID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -177,3 +188,79 @@ func GetMyPermissions(c *gin.Context) {
 
 	c.JSON(http.StatusOK, env.PermissionList)
 }
+
+// GET /api/v1/permissions
+// Lists every permission the API checks, whether or not a role grants it
+func ListPermissionCatalogue(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": middlewares.RegisteredPermissions()})
+}
+
+func GetRolePermissions(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	roleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
+		return
+	}
+
+	permissions, err := services.GetPermissionsByRole(env, strconv.FormatUint(roleID, 10))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	permissionList := make([]string, len(permissions))
+	for i, permission := range permissions {
+		permissionList[i] = permission.Name
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": permissionList})
+}
+
+type AttachPermissionsRequest struct {
+	PermissionList []string `json:"permission_list" binding:"required"`
+}
+
+func AttachPermissionsToRole(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	roleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
+		return
+	}
+
+	var req AttachPermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.AttachPermissionsToRole(env, strconv.FormatUint(roleID, 10), req.PermissionList); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Permissions attached successfully"})
+}
+
+func DetachPermissionFromRole(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	roleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
+		return
+	}
+
+	if err := services.DetachPermissionFromRole(env, strconv.FormatUint(roleID, 10), c.Param("permission")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Permission detached successfully"})
+}
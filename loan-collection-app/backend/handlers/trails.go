@@ -1,62 +1,94 @@
 package handlers
 
 import (
+	"backend/models"
+	"backend/services"
+	"errors"
 	"net/http"
-	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // Each trail is a record of communication attempts
-type Trail struct {
-	TrailID     int    `json:"trail_id"`
-	CaseID      int    `json:"case_id"`
-	Contacted   bool   `json:"contacted"`
-	PaymentDate string `json:"payment_date"` // If user says they'll pay on a date
-	Remarks     string `json:"remarks"`
+type AddTrailRequest struct {
+	Disposition  string `json:"disposition" binding:"required"`
+	PaymentDate  string `json:"payment_date"` // If user says they'll pay on a date
+	FollowUpDate string `json:"follow_up_date"`
+	Remarks      string `json:"remarks"`
 }
 
-var trails []Trail
-var trailIDCounter = 1
-
 // POST /api/cases/:caseID/trails
 func AddTrail(c *gin.Context) {
-	caseIDStr := c.Param("caseID")
-	caseID, _ := strconv.Atoi(caseIDStr)
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
 
-	var newTrail Trail
-	if err := c.ShouldBindJSON(&newTrail); err != nil {
+	var req AddTrailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid trail data"})
 		return
 	}
 
-	newTrail.TrailID = trailIDCounter
-	newTrail.CaseID = caseID
-	trailIDCounter++
+	paymentDate, err := parseOptionalDate(req.PaymentDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment date"})
+		return
+	}
+	followUpDate, err := parseOptionalDate(req.FollowUpDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid follow up date"})
+		return
+	}
+
+	trail := models.Trail{
+		CaseID:       c.Param("caseID"),
+		Disposition:  req.Disposition,
+		PaymentDate:  paymentDate,
+		FollowUpDate: followUpDate,
+		Remarks:      req.Remarks,
+	}
 
-	trails = append(trails, newTrail)
-	c.JSON(http.StatusOK, newTrail)
+	if err := services.AddTrail(env, &trail); err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTrail):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Case not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error adding trail"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Trail added successfully",
+		"trail_id": trail.ID,
+	})
 }
 
 // GET /api/cases/:caseID/trails
 func GetTrails(c *gin.Context) {
-	caseIDStr := c.Param("caseID")
-	caseID, _ := strconv.Atoi(caseIDStr)
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
 
-	var caseTrails []Trail
-	for _, t := range trails {
-		if t.CaseID == caseID {
-			caseTrails = append(caseTrails, t)
-		}
+	trails, err := services.GetTrails(env, c.Param("caseID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	c.JSON(http.StatusOK, caseTrails)
+	c.JSON(http.StatusOK, gin.H{"data": trails})
 }
 
-// GET /api/cases/:caseID/payment-link
-func GetPaymentLink(c *gin.Context) {
-	caseIDStr := c.Param("caseID")
-	// Just return a dummy link
-	paymentLink := "https://payment.example.com/pay?caseID=" + caseIDStr
-	c.JSON(http.StatusOK, gin.H{"payment_link": paymentLink})
+// parseOptionalDate parses a YYYY-MM-DD date, returning nil when empty
+func parseOptionalDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
 }
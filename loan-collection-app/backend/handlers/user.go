@@ -4,6 +4,7 @@ import (
 	"backend/constants"
 	"backend/models"
 	"backend/services"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -57,6 +58,18 @@ func ListAllUsers(c *gin.Context) {
 	val, _ := c.Get("env")
 	env := val.(*models.Env)
 
+	format, err := exportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if format != "" {
+		writeExport(c, format, "users", userExportColumns, exportAll(func() ([]models.UserWithRoles, error) {
+			return services.ListAllUsers(env)
+		}))
+		return
+	}
+
 	users, err := services.ListAllUsers(env)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -65,24 +78,50 @@ func ListAllUsers(c *gin.Context) {
 
 	usersResponse := make([]ListUserResponse, len(users))
 	for i, user := range users {
-		roles, err := services.GetRolesByUser(env, user.ID)
-		if err != nil {
-			env.Logger.Error(err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		roleList := make([]string, len(roles))
-		for j, role := range roles {
-			roleList[j] = role.RoleName
-		}
 		usersResponse[i] = ListUserResponse{
 			Id:       user.ID,
 			Username: user.Username,
 			Email:    user.Email,
 			IsActive: user.IsActive,
-			Role:     roleList,
+			Role:     user.RoleNames,
 		}
 	}
 
 	c.JSON(http.StatusOK, usersResponse)
 }
+
+type UnlockUserRequest struct {
+	IPAddress string `json:"ip_address"`
+}
+
+// UnlockUserHandler lifts a login lockout, optionally together with the lockout of an IP
+func UnlockUserHandler(c *gin.Context) {
+	val, _ := c.Get("env")
+	env := val.(*models.Env)
+
+	userID := c.Param("user_id")
+	if !isUUID(userID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return
+	}
+
+	var req UnlockUserRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	err := services.UnlockUser(env, env.AuthDtos.User.ID, userID, req.IPAddress)
+	if errors.Is(err, services.ErrUserNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked successfully"})
+}
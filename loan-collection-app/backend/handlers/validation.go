@@ -0,0 +1,10 @@
+package handlers
+
+import "github.com/google/uuid"
+
+// isUUID reports whether an ID taken from the request is a well formed UUID,
+// so malformed ones are rejected before they reach the database
+func isUUID(value string) bool {
+	_, err := uuid.Parse(value)
+	return err == nil
+}
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS case_user_map;
+DROP TABLE IF EXISTS agency_case_map;
+DROP TABLE IF EXISTS cases;
+DROP TABLE IF EXISTS agency_user_map;
+DROP TABLE IF EXISTS agencies;
+DROP TABLE IF EXISTS user_role_map;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,104 @@
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE IF NOT EXISTS users (
+    id            UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    username      VARCHAR(50)  NOT NULL UNIQUE,
+    email         VARCHAR(100),
+    password_hash TEXT         NOT NULL,
+    profile_data  JSONB,
+    is_active     BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at    TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS roles (
+    id          SERIAL PRIMARY KEY,
+    role_name   VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS user_role_map (
+    id          SERIAL PRIMARY KEY,
+    user_id     UUID      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role_id     INTEGER   NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    is_active   BOOLEAN   NOT NULL DEFAULT TRUE,
+    assigned_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_role_map_user_id ON user_role_map (user_id);
+CREATE INDEX IF NOT EXISTS idx_user_role_map_role_id ON user_role_map (role_id);
+
+CREATE TABLE IF NOT EXISTS agencies (
+    id             UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    agency_name    VARCHAR(255) NOT NULL,
+    status         VARCHAR(255) NOT NULL,
+    agency_details JSONB        NOT NULL,
+    created_at     TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS agency_user_map (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    agency_id   UUID         NOT NULL REFERENCES agencies (id) ON DELETE CASCADE,
+    user_id     UUID         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    manager_id  UUID         REFERENCES users (id) ON DELETE SET NULL,
+    agency_role VARCHAR(255) NOT NULL,
+    is_active   BOOLEAN      NOT NULL DEFAULT TRUE,
+    assigned_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_agency_user_map_agency_id ON agency_user_map (agency_id);
+CREATE INDEX IF NOT EXISTS idx_agency_user_map_user_id ON agency_user_map (user_id);
+
+CREATE TABLE IF NOT EXISTS cases (
+    id                       UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    loan_id                  VARCHAR(50),
+    external_customer_id     VARCHAR(50),
+    emi_amount               NUMERIC(10, 2),
+    principal_outstanding    NUMERIC(10, 2),
+    interest_outstanding     NUMERIC(10, 2),
+    case_status              VARCHAR(50),
+    emi_date                 DATE,
+    dpd_bucket               VARCHAR(50),
+    dpd                      INTEGER,
+    disbursal_date           DATE,
+    insurance_active         BOOLEAN DEFAULT FALSE,
+    loan_description         TEXT,
+    emis_paid_till_date      INTEGER,
+    emis_pending             INTEGER,
+    bounce_charges           NUMERIC(10, 2),
+    nach_presentation_status VARCHAR(50),
+    created_at               TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at               TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_cases_loan_id ON cases (loan_id);
+CREATE INDEX IF NOT EXISTS idx_cases_external_customer_id ON cases (external_customer_id);
+CREATE INDEX IF NOT EXISTS idx_cases_case_status ON cases (case_status);
+CREATE INDEX IF NOT EXISTS idx_cases_dpd_bucket ON cases (dpd_bucket);
+
+CREATE TABLE IF NOT EXISTS agency_case_map (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    agency_id   UUID      NOT NULL REFERENCES agencies (id) ON DELETE CASCADE,
+    case_id     UUID      NOT NULL REFERENCES cases (id) ON DELETE CASCADE,
+    assigned_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_agency_case_map_agency_id ON agency_case_map (agency_id);
+CREATE INDEX IF NOT EXISTS idx_agency_case_map_case_id ON agency_case_map (case_id);
+
+CREATE TABLE IF NOT EXISTS case_user_map (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    case_id     UUID      NOT NULL REFERENCES cases (id) ON DELETE CASCADE,
+    user_id     UUID      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    assigned_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_case_user_map_case_id ON case_user_map (case_id);
+CREATE INDEX IF NOT EXISTS idx_case_user_map_user_id ON case_user_map (user_id);
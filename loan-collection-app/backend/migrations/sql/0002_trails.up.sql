@@ -0,0 +1,13 @@
+CREATE TABLE trails (
+    id             UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    case_id        UUID        NOT NULL REFERENCES cases (id) ON DELETE CASCADE,
+    user_id        UUID        NOT NULL REFERENCES users (id),
+    disposition    VARCHAR(50) NOT NULL,
+    payment_date   DATE,
+    follow_up_date DATE,
+    remarks        TEXT,
+    created_at     TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_trails_case_id_created_at ON trails (case_id, created_at DESC);
+CREATE INDEX idx_trails_user_id ON trails (user_id);
@@ -0,0 +1,33 @@
+CREATE TABLE payment_links (
+    id                 UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    case_id            UUID           NOT NULL REFERENCES cases (id) ON DELETE CASCADE,
+    created_by         UUID           NOT NULL REFERENCES users (id),
+    amount_type        VARCHAR(20)    NOT NULL,
+    amount             NUMERIC(10, 2) NOT NULL,
+    provider           VARCHAR(50)    NOT NULL,
+    provider_reference VARCHAR(255),
+    url                TEXT,
+    status             VARCHAR(20)    NOT NULL,
+    expires_at         TIMESTAMP      NOT NULL,
+    created_at         TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at         TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_payment_links_case_id ON payment_links (case_id);
+CREATE INDEX idx_payment_links_provider_reference ON payment_links (provider, provider_reference);
+
+CREATE TABLE payments (
+    id                UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    case_id           UUID           NOT NULL REFERENCES cases (id) ON DELETE CASCADE,
+    payment_link_id   UUID           REFERENCES payment_links (id) ON DELETE SET NULL,
+    source            VARCHAR(50)    NOT NULL,
+    event_id          VARCHAR(255)   UNIQUE,
+    mode              VARCHAR(50),
+    payment_reference VARCHAR(255),
+    amount            NUMERIC(10, 2) NOT NULL,
+    paid_at           TIMESTAMP      NOT NULL,
+    created_at        TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_payments_case_id ON payments (case_id);
+CREATE INDEX idx_payments_paid_at ON payments (paid_at);
@@ -0,0 +1,13 @@
+CREATE TABLE customers (
+    id                   UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    external_customer_id VARCHAR(50)  NOT NULL UNIQUE,
+    name                 VARCHAR(255),
+    email                VARCHAR(100),
+    phones               JSONB        NOT NULL DEFAULT '[]',
+    addresses            JSONB        NOT NULL DEFAULT '[]',
+    pincode              VARCHAR(10),
+    created_at           TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at           TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_customers_pincode ON customers (pincode);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS import_jobs;
+DROP INDEX IF EXISTS idx_cases_open_loan_id;
@@ -0,0 +1,37 @@
+-- Earlier uploads could open a loan more than once. Keep the oldest open
+-- case of each loan, which carries its allocation and trail history, and
+-- withdraw the later copies so the index below can be built.
+UPDATE cases
+SET case_status = 'WITHDRAWN',
+    updated_at  = CURRENT_TIMESTAMP
+WHERE id IN (
+    SELECT id
+    FROM (
+        SELECT id,
+               ROW_NUMBER() OVER (PARTITION BY loan_id ORDER BY created_at, id) AS copy
+        FROM cases
+        WHERE loan_id IS NOT NULL
+          AND case_status NOT IN ('SETTLED', 'CLOSED', 'WITHDRAWN')
+    ) open_cases
+    WHERE copy > 1
+);
+
+-- A loan can only have one case under collection at a time
+CREATE UNIQUE INDEX idx_cases_open_loan_id ON cases (loan_id)
+    WHERE case_status NOT IN ('SETTLED', 'CLOSED', 'WITHDRAWN');
+
+CREATE TABLE import_jobs (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    file_name   VARCHAR(255) NOT NULL,
+    file_size   BIGINT       NOT NULL,
+    mode        VARCHAR(20)  NOT NULL,
+    status      VARCHAR(20)  NOT NULL,
+    created_by  UUID         NOT NULL REFERENCES users (id),
+    bytes_read  BIGINT       NOT NULL DEFAULT 0,
+    report      JSONB,
+    error       TEXT,
+    started_at  TIMESTAMP,
+    finished_at TIMESTAMP,
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
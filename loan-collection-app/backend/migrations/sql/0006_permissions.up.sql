@@ -0,0 +1,105 @@
+CREATE TABLE permissions (
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE role_permissions (
+    role_id       INTEGER   NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    permission_id INTEGER   NOT NULL REFERENCES permissions (id) ON DELETE CASCADE,
+    created_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE INDEX idx_role_permissions_permission_id ON role_permissions (permission_id);
+
+-- Carry over the grants that used to be compiled into RolePermissionsMap
+INSERT INTO permissions (name) VALUES
+    ('view_users'),
+    ('create_user'),
+    ('update_user'),
+    ('delete_user'),
+    ('view_roles'),
+    ('create_role'),
+    ('update_role'),
+    ('delete_role'),
+    ('assign_role_to_user'),
+    ('remove_role_from_user'),
+    ('view_agencies'),
+    ('create_agency'),
+    ('assign_agency_user'),
+    ('assign_case'),
+    ('delete_agency'),
+    ('view_agency_users'),
+    ('view_unassigned_users'),
+    ('assign_user_to_agency'),
+    ('view_agency_user_mapping'),
+    ('upload_cases'),
+    ('view_unassigned_cases'),
+    ('assign_cases'),
+    ('view_all_agency_users'),
+    ('view_permissions'),
+    ('manage_role_permissions'),
+    ('view_agency_cases'),
+    ('assign_agency_cases'),
+    ('view_my_cases'),
+    ('view_trails'),
+    ('generate_payment_link'),
+    ('add_trail'),
+    ('view_my_permissions');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id
+FROM (VALUES
+    ('admin', 'view_users'),
+    ('admin', 'create_user'),
+    ('admin', 'update_user'),
+    ('admin', 'delete_user'),
+    ('admin', 'view_roles'),
+    ('admin', 'create_role'),
+    ('admin', 'update_role'),
+    ('admin', 'delete_role'),
+    ('admin', 'assign_role_to_user'),
+    ('admin', 'remove_role_from_user'),
+    ('admin', 'view_agencies'),
+    ('admin', 'create_agency'),
+    ('admin', 'assign_agency_user'),
+    ('admin', 'assign_case'),
+    ('admin', 'delete_agency'),
+    ('admin', 'view_agency_users'),
+    ('admin', 'view_unassigned_users'),
+    ('admin', 'assign_user_to_agency'),
+    ('admin', 'view_agency_user_mapping'),
+    ('admin', 'upload_cases'),
+    ('admin', 'view_unassigned_cases'),
+    ('admin', 'assign_cases'),
+    ('admin', 'view_all_agency_users'),
+    ('admin', 'view_permissions'),
+    ('admin', 'manage_role_permissions'),
+    ('agency_admin', 'view_agency_cases'),
+    ('agency_admin', 'assign_agency_cases'),
+    ('agency_admin', 'view_roles'),
+    ('agency_admin', 'view_users'),
+    ('agency_admin', 'view_agency_users'),
+    ('agency_admin', 'view_agency_user_mapping'),
+    ('agency_admin', 'assign_user_to_agency'),
+    ('agency_admin', 'view_agencies'),
+    ('agency_admin', 'view_unassigned_users'),
+    ('agency_admin', 'view_all_agency_users'),
+    ('agency_admin', 'assign_agency_user'),
+    ('agency_admin', 'view_unassigned_cases'),
+    ('agent', 'view_my_cases'),
+    ('agent', 'view_trails'),
+    ('agent', 'generate_payment_link'),
+    ('agent', 'add_trail'),
+    ('bank_admin', 'upload_cases'),
+    ('bank_admin', 'view_unassigned_cases'),
+    ('bank_admin', 'assign_cases'),
+    ('bank_admin', 'view_agency_users'),
+    ('bank_admin', 'assign_agency_cases'),
+    ('bank_admin', 'view_agencies'),
+    ('default', 'view_my_permissions')
+) AS grants (role_name, permission_name)
+JOIN roles ON roles.role_name = grants.role_name
+JOIN permissions ON permissions.name = grants.permission_name;
@@ -0,0 +1,6 @@
+DELETE FROM permissions
+WHERE name IN ('view_all_cases', 'view_lender_cases', 'view_lenders', 'create_lender', 'assign_lender_user');
+
+ALTER TABLE cases DROP COLUMN IF EXISTS lender_id;
+DROP TABLE IF EXISTS lender_user_map;
+DROP TABLE IF EXISTS lenders;
@@ -0,0 +1,47 @@
+CREATE TABLE lenders (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    lender_name VARCHAR(255) NOT NULL,
+    status      VARCHAR(50)  NOT NULL DEFAULT 'ACTIVE',
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE lender_user_map (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    lender_id   UUID      NOT NULL REFERENCES lenders (id) ON DELETE CASCADE,
+    user_id     UUID      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    is_active   BOOLEAN   NOT NULL DEFAULT TRUE,
+    assigned_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_lender_user_map_user_id ON lender_user_map (user_id);
+
+ALTER TABLE cases ADD COLUMN lender_id UUID REFERENCES lenders (id);
+
+CREATE INDEX idx_cases_lender_id ON cases (lender_id);
+
+INSERT INTO permissions (name) VALUES
+    ('view_all_cases'),
+    ('view_lender_cases'),
+    ('view_lenders'),
+    ('create_lender'),
+    ('assign_lender_user')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id
+FROM (VALUES
+    ('admin', 'view_all_cases'),
+    ('admin', 'view_lenders'),
+    ('admin', 'create_lender'),
+    ('admin', 'assign_lender_user'),
+    ('agency_admin', 'view_my_cases'),
+    ('agency_admin', 'view_trails'),
+    ('bank_admin', 'view_lender_cases'),
+    ('bank_admin', 'view_my_cases'),
+    ('bank_admin', 'view_trails')
+) AS grants (role_name, permission_name)
+JOIN roles ON roles.role_name = grants.role_name
+JOIN permissions ON permissions.name = grants.permission_name
+ON CONFLICT DO NOTHING;
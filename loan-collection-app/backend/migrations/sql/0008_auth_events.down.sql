@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'unlock_user';
+
+DROP TABLE IF EXISTS auth_events;
@@ -0,0 +1,24 @@
+CREATE TABLE auth_events (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    event_type   VARCHAR(50) NOT NULL,
+    username     VARCHAR(50),
+    user_id      UUID REFERENCES users (id) ON DELETE SET NULL,
+    ip_address   VARCHAR(64),
+    attempts     INT         NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP,
+    actor_id     UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at   TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_auth_events_username ON auth_events (username, created_at);
+
+INSERT INTO permissions (name) VALUES
+    ('unlock_user')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id
+FROM roles
+JOIN permissions ON permissions.name = 'unlock_user'
+WHERE roles.role_name = 'admin'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_cases_created_at;
+DROP INDEX IF EXISTS idx_cases_emi_date;
+DROP INDEX IF EXISTS idx_cases_dpd;
@@ -0,0 +1,3 @@
+CREATE INDEX idx_cases_dpd ON cases (dpd);
+CREATE INDEX idx_cases_emi_date ON cases (emi_date);
+CREATE INDEX idx_cases_created_at ON cases (created_at);
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'update_case_status';
+
+DROP TABLE IF EXISTS case_status_history;
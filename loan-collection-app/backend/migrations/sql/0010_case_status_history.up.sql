@@ -0,0 +1,33 @@
+CREATE TABLE case_status_history (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    case_id     UUID        NOT NULL REFERENCES cases (id) ON DELETE CASCADE,
+    from_status VARCHAR(50) NOT NULL,
+    to_status   VARCHAR(50) NOT NULL,
+    reason_code VARCHAR(50) NOT NULL,
+    remarks     TEXT,
+    actor_id    UUID REFERENCES users (id),
+    created_at  TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_case_status_history_case_id_created_at ON case_status_history (case_id, created_at DESC);
+
+-- Move free text statuses onto the lifecycle
+UPDATE cases SET case_status = CASE
+    WHEN EXISTS (SELECT 1 FROM case_user_map WHERE case_user_map.case_id = cases.id) THEN 'ASSIGNED_TO_AGENT'
+    WHEN EXISTS (SELECT 1 FROM agency_case_map WHERE agency_case_map.case_id = cases.id) THEN 'ALLOCATED_TO_AGENCY'
+    ELSE 'NEW'
+END
+WHERE case_status IS NULL
+   OR case_status NOT IN ('NEW', 'ALLOCATED_TO_AGENCY', 'ASSIGNED_TO_AGENT', 'IN_PROGRESS', 'PTP', 'BROKEN_PTP',
+                          'PARTIALLY_PAID', 'SETTLED', 'CLOSED', 'WITHDRAWN');
+
+INSERT INTO permissions (name) VALUES
+    ('update_case_status')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id
+FROM roles
+JOIN permissions ON permissions.name = 'update_case_status'
+WHERE roles.role_name IN ('admin', 'agency_admin', 'agent', 'bank_admin')
+ON CONFLICT DO NOTHING;
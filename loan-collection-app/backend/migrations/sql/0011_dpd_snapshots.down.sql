@@ -0,0 +1 @@
+DROP TABLE IF EXISTS dpd_snapshots;
@@ -0,0 +1,16 @@
+CREATE TABLE dpd_snapshots (
+    id                    UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    snapshot_date         DATE      NOT NULL,
+    case_id               UUID      NOT NULL REFERENCES cases (id) ON DELETE CASCADE,
+    lender_id             UUID REFERENCES lenders (id),
+    agency_id             UUID REFERENCES agencies (id) ON DELETE SET NULL,
+    case_status           VARCHAR(50),
+    dpd                   INTEGER,
+    dpd_bucket            VARCHAR(50),
+    principal_outstanding NUMERIC(10, 2),
+    interest_outstanding  NUMERIC(10, 2),
+    created_at            TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_dpd_snapshots_date_case_id ON dpd_snapshots (snapshot_date, case_id);
+CREATE INDEX idx_dpd_snapshots_case_id ON dpd_snapshots (case_id);
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name IN ('view_allocation_rules', 'manage_allocation_rules', 'allocate_cases');
+
+DROP TABLE IF EXISTS agency_allocation_rules;
@@ -0,0 +1,25 @@
+CREATE TABLE agency_allocation_rules (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    agency_id   UUID           NOT NULL UNIQUE REFERENCES agencies (id) ON DELETE CASCADE,
+    dpd_buckets JSONB          NOT NULL DEFAULT '[]',
+    loan_types  JSONB          NOT NULL DEFAULT '[]',
+    pincodes    JSONB          NOT NULL DEFAULT '[]',
+    capacity    INTEGER,
+    weight      NUMERIC(10, 2) NOT NULL DEFAULT 1,
+    is_active   BOOLEAN        NOT NULL DEFAULT TRUE,
+    created_at  TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO permissions (name) VALUES
+    ('view_allocation_rules'),
+    ('manage_allocation_rules'),
+    ('allocate_cases')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id
+FROM roles
+JOIN permissions ON permissions.name IN ('view_allocation_rules', 'manage_allocation_rules', 'allocate_cases')
+WHERE roles.role_name IN ('admin', 'bank_admin')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+ALTER TABLE agency_user_map
+    DROP COLUMN IF EXISTS territories,
+    DROP COLUMN IF EXISTS max_open_cases;
@@ -0,0 +1,3 @@
+ALTER TABLE agency_user_map
+    ADD COLUMN max_open_cases INTEGER,
+    ADD COLUMN territories    JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,22 @@
+DELETE FROM permissions WHERE name IN ('recall_cases', 'reassign_agency_cases');
+
+DROP INDEX IF EXISTS idx_case_user_map_active_case_id;
+DROP INDEX IF EXISTS idx_agency_case_map_active_case_id;
+
+-- Mappings that have ended cannot be told apart once the columns are gone
+DELETE FROM case_user_map WHERE unassigned_at IS NOT NULL;
+DELETE FROM agency_case_map WHERE unassigned_at IS NOT NULL;
+
+ALTER TABLE case_user_map
+    DROP COLUMN IF EXISTS unassign_remarks,
+    DROP COLUMN IF EXISTS unassign_reason,
+    DROP COLUMN IF EXISTS unassigned_by,
+    DROP COLUMN IF EXISTS unassigned_at,
+    DROP COLUMN IF EXISTS assigned_by;
+
+ALTER TABLE agency_case_map
+    DROP COLUMN IF EXISTS unassign_remarks,
+    DROP COLUMN IF EXISTS unassign_reason,
+    DROP COLUMN IF EXISTS unassigned_by,
+    DROP COLUMN IF EXISTS unassigned_at,
+    DROP COLUMN IF EXISTS assigned_by;
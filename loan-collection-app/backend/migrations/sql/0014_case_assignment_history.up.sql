@@ -0,0 +1,55 @@
+ALTER TABLE agency_case_map
+    ADD COLUMN assigned_by      UUID REFERENCES users (id) ON DELETE SET NULL,
+    ADD COLUMN unassigned_at    TIMESTAMP,
+    ADD COLUMN unassigned_by    UUID REFERENCES users (id) ON DELETE SET NULL,
+    ADD COLUMN unassign_reason  VARCHAR(50),
+    ADD COLUMN unassign_remarks TEXT;
+
+ALTER TABLE case_user_map
+    ADD COLUMN assigned_by      UUID REFERENCES users (id) ON DELETE SET NULL,
+    ADD COLUMN unassigned_at    TIMESTAMP,
+    ADD COLUMN unassigned_by    UUID REFERENCES users (id) ON DELETE SET NULL,
+    ADD COLUMN unassign_reason  VARCHAR(50),
+    ADD COLUMN unassign_remarks TEXT;
+
+-- Only the latest mapping of a case stays current; earlier ones end when the
+-- next one starts
+UPDATE agency_case_map
+SET unassigned_at = superseded.next_assigned_at, unassign_reason = 'REALLOCATED'
+FROM (
+    SELECT id, LEAD(assigned_at) OVER (PARTITION BY case_id ORDER BY assigned_at, id) AS next_assigned_at,
+           ROW_NUMBER() OVER (PARTITION BY case_id ORDER BY assigned_at DESC, id DESC) AS position
+    FROM agency_case_map
+) AS superseded
+WHERE agency_case_map.id = superseded.id AND superseded.position > 1;
+
+UPDATE case_user_map
+SET unassigned_at = superseded.next_assigned_at, unassign_reason = 'REASSIGNED'
+FROM (
+    SELECT id, LEAD(assigned_at) OVER (PARTITION BY case_id ORDER BY assigned_at, id) AS next_assigned_at,
+           ROW_NUMBER() OVER (PARTITION BY case_id ORDER BY assigned_at DESC, id DESC) AS position
+    FROM case_user_map
+) AS superseded
+WHERE case_user_map.id = superseded.id AND superseded.position > 1;
+
+CREATE UNIQUE INDEX idx_agency_case_map_active_case_id ON agency_case_map (case_id) WHERE unassigned_at IS NULL;
+CREATE UNIQUE INDEX idx_case_user_map_active_case_id ON case_user_map (case_id) WHERE unassigned_at IS NULL;
+
+INSERT INTO permissions (name) VALUES
+    ('recall_cases'),
+    ('reassign_agency_cases')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id
+FROM roles
+JOIN permissions ON permissions.name = 'recall_cases'
+WHERE roles.role_name IN ('admin', 'bank_admin')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id
+FROM roles
+JOIN permissions ON permissions.name = 'reassign_agency_cases'
+WHERE roles.role_name IN ('admin', 'agency_admin')
+ON CONFLICT DO NOTHING;
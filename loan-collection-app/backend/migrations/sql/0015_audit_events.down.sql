@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE name = 'view_audit_events';
+
+DROP TABLE IF EXISTS audit_events;
+DROP FUNCTION IF EXISTS reject_audit_event_change();
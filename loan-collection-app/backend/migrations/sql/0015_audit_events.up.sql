@@ -0,0 +1,41 @@
+CREATE TABLE audit_events (
+    id             UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    actor_id       UUID,
+    actor_username VARCHAR(50),
+    action         VARCHAR(255) NOT NULL,
+    entity_type    VARCHAR(50),
+    entity_id      VARCHAR(100),
+    before         JSONB,
+    after          JSONB,
+    status_code    INT          NOT NULL,
+    ip_address     VARCHAR(64),
+    request_id     VARCHAR(64),
+    created_at     TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_events_created_at ON audit_events (created_at DESC);
+CREATE INDEX idx_audit_events_actor_id_created_at ON audit_events (actor_id, created_at DESC);
+CREATE INDEX idx_audit_events_entity ON audit_events (entity_type, entity_id, created_at DESC);
+CREATE INDEX idx_audit_events_request_id ON audit_events (request_id);
+
+-- The audit log is append only
+CREATE FUNCTION reject_audit_event_change() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_event_change();
+
+INSERT INTO permissions (name) VALUES
+    ('view_audit_events')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id
+FROM roles
+JOIN permissions ON permissions.name = 'view_audit_events'
+WHERE roles.role_name IN ('admin')
+ON CONFLICT DO NOTHING;
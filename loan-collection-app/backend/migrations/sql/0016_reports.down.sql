@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE name = 'view_reports';
+
+DROP INDEX IF EXISTS idx_case_user_map_assigned_at;
+DROP INDEX IF EXISTS idx_agency_case_map_assigned_at;
@@ -0,0 +1,13 @@
+CREATE INDEX idx_agency_case_map_assigned_at ON agency_case_map (assigned_at);
+CREATE INDEX idx_case_user_map_assigned_at ON case_user_map (assigned_at);
+
+INSERT INTO permissions (name) VALUES
+    ('view_reports')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id
+FROM roles
+JOIN permissions ON permissions.name = 'view_reports'
+WHERE roles.role_name IN ('admin', 'agency_admin', 'bank_admin')
+ON CONFLICT DO NOTHING;
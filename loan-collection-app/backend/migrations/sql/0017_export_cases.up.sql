@@ -0,0 +1,10 @@
+INSERT INTO permissions (name) VALUES
+    ('export_cases')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id
+FROM roles
+JOIN permissions ON permissions.name = 'export_cases'
+WHERE roles.role_name IN ('admin', 'agency_admin', 'bank_admin')
+ON CONFLICT DO NOTHING;
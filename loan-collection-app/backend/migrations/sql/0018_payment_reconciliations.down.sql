@@ -0,0 +1,5 @@
+DELETE FROM permissions WHERE name = 'reconcile_payments';
+
+DROP INDEX IF EXISTS idx_payments_case_id_payment_reference;
+DROP TABLE IF EXISTS payment_reconciliation_lines;
+DROP TABLE IF EXISTS payment_reconciliations;
@@ -0,0 +1,39 @@
+CREATE TABLE payment_reconciliations (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    file_name  VARCHAR(255) NOT NULL,
+    lender_id  UUID REFERENCES lenders (id),
+    created_by UUID         NOT NULL REFERENCES users (id),
+    summary    JSONB        NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_payment_reconciliations_created_at ON payment_reconciliations (created_at DESC);
+
+CREATE TABLE payment_reconciliation_lines (
+    id                UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    reconciliation_id UUID        NOT NULL REFERENCES payment_reconciliations (id) ON DELETE CASCADE,
+    row_number        INTEGER     NOT NULL,
+    loan_id           VARCHAR(50),
+    amount            NUMERIC(10, 2),
+    paid_at           TIMESTAMP,
+    mode              VARCHAR(50),
+    reference         VARCHAR(255),
+    status            VARCHAR(20) NOT NULL,
+    reason            TEXT,
+    case_id           UUID REFERENCES cases (id) ON DELETE SET NULL,
+    payment_id        UUID REFERENCES payments (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_payment_reconciliation_lines_reconciliation_id ON payment_reconciliation_lines (reconciliation_id, row_number);
+CREATE INDEX idx_payments_case_id_payment_reference ON payments (case_id, payment_reference);
+
+INSERT INTO permissions (name) VALUES
+    ('reconcile_payments')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id
+FROM roles
+JOIN permissions ON permissions.name = 'reconcile_payments'
+WHERE roles.role_name IN ('admin', 'bank_admin')
+ON CONFLICT DO NOTHING;
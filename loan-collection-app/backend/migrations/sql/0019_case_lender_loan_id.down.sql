@@ -0,0 +1,5 @@
+-- Fails while two lenders have an open case for the same loan ID
+DROP INDEX IF EXISTS idx_cases_open_lender_loan_id;
+
+CREATE UNIQUE INDEX idx_cases_open_loan_id ON cases (loan_id)
+    WHERE case_status NOT IN ('SETTLED', 'CLOSED', 'WITHDRAWN');
@@ -0,0 +1,6 @@
+-- Loan IDs are only unique within a lender, so each lender can have its own
+-- open case for a loan ID another lender also uses
+DROP INDEX IF EXISTS idx_cases_open_loan_id;
+
+CREATE UNIQUE INDEX idx_cases_open_lender_loan_id ON cases (lender_id, loan_id)
+    WHERE case_status NOT IN ('SETTLED', 'CLOSED', 'WITHDRAWN');
@@ -3,6 +3,8 @@ package models
 import (
 	"encoding/json"
 	"time"
+
+	"gorm.io/datatypes"
 )
 
 type Agency struct {
@@ -15,38 +17,55 @@ type Agency struct {
 }
 
 type AgencyUserMap struct {
-	ID         string    `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
-	AgencyID   string    `gorm:"type:uuid;not null"`
-	UserID     string    `gorm:"type:uuid;not null"`
-	ManagerID  *string   `gorm:"type:uuid;null"`
-	AgencyRole string    `gorm:"type:varchar(255);not null"`
-	IsActive   bool      `gorm:"type:boolean;not null"`
-	AssignedAt time.Time `gorm:"type:timestamp;not null"`
-	UpdatedAt  time.Time `gorm:"type:timestamp;not null"`
+	ID           string                      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	AgencyID     string                      `gorm:"type:uuid;not null"`
+	UserID       string                      `gorm:"type:uuid;not null"`
+	ManagerID    *string                     `gorm:"type:uuid;null"`
+	AgencyRole   string                      `gorm:"type:varchar(255);not null"`
+	IsActive     bool                        `gorm:"type:boolean;not null"`
+	MaxOpenCases *int                        `gorm:"type:integer;null"`
+	Territories  datatypes.JSONSlice[string] `gorm:"type:jsonb;not null;default:'[]'"`
+	AssignedAt   time.Time                   `gorm:"type:timestamp;not null"`
+	UpdatedAt    time.Time                   `gorm:"type:timestamp;not null"`
 }
 
 func (AgencyUserMap) TableName() string {
 	return "agency_user_map"
 }
 
+// CaseUserMap assigns a case to an agent. A row stays in place when the case
+// moves on; UnassignedAt ends it, so a case has at most one row with
+// UnassignedAt unset.
 type CaseUserMap struct {
-	ID         string    `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
-	CaseID     string    `gorm:"type:uuid;not null"`
-	UserID     string    `gorm:"type:uuid;not null"`
-	AssignedAt time.Time `gorm:"type:timestamp;not null"`
-	UpdatedAt  time.Time `gorm:"type:timestamp;not null"`
+	ID              string     `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	CaseID          string     `gorm:"type:uuid;not null"`
+	UserID          string     `gorm:"type:uuid;not null"`
+	AssignedAt      time.Time  `gorm:"type:timestamp;not null"`
+	AssignedBy      *string    `gorm:"type:uuid"`
+	UnassignedAt    *time.Time `gorm:"type:timestamp"`
+	UnassignedBy    *string    `gorm:"type:uuid"`
+	UnassignReason  *string    `gorm:"type:varchar(50)"`
+	UnassignRemarks *string    `gorm:"type:text"`
+	UpdatedAt       time.Time  `gorm:"type:timestamp;not null"`
 }
 
 func (CaseUserMap) TableName() string {
 	return "case_user_map"
 }
 
+// AgencyCaseMap allocates a case to an agency, effective-dated like
+// CaseUserMap
 type AgencyCaseMap struct {
-	ID         string    `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
-	AgencyID   string    `gorm:"type:uuid;not null"`
-	CaseID     string    `gorm:"type:uuid;not null"`
-	AssignedAt time.Time `gorm:"type:timestamp;not null"`
-	UpdatedAt  time.Time `gorm:"type:timestamp;not null"`
+	ID              string     `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	AgencyID        string     `gorm:"type:uuid;not null"`
+	CaseID          string     `gorm:"type:uuid;not null"`
+	AssignedAt      time.Time  `gorm:"type:timestamp;not null"`
+	AssignedBy      *string    `gorm:"type:uuid"`
+	UnassignedAt    *time.Time `gorm:"type:timestamp"`
+	UnassignedBy    *string    `gorm:"type:uuid"`
+	UnassignReason  *string    `gorm:"type:varchar(50)"`
+	UnassignRemarks *string    `gorm:"type:text"`
+	UpdatedAt       time.Time  `gorm:"type:timestamp;not null"`
 }
 
 func (AgencyCaseMap) TableName() string {
@@ -54,9 +73,12 @@ func (AgencyCaseMap) TableName() string {
 }
 
 type AgencyUserDetails struct {
-	UserID     string  `json:"user_id"`
-	Username   string  `json:"username"`
-	Email      string  `json:"email"`
-	AgencyRole string  `json:"agency_role"`
-	ManagerID  *string `json:"manager_id"`
+	UserID       string                      `json:"user_id"`
+	Username     string                      `json:"username"`
+	Email        string                      `json:"email"`
+	AgencyRole   string                      `json:"agency_role"`
+	ManagerID    *string                     `json:"manager_id"`
+	IsActive     bool                        `json:"is_active"`
+	MaxOpenCases *int                        `json:"max_open_cases"`
+	Territories  datatypes.JSONSlice[string] `json:"territories"`
 }
@@ -0,0 +1,25 @@
+package models
+
+type AgentAssignment struct {
+	CaseID   string `json:"case_id" binding:"required"`
+	LoanID   string `json:"loan_id,omitempty"`
+	UserID   string `json:"user_id" binding:"required"`
+	Username string `json:"username,omitempty"`
+}
+
+type AgentAssignmentSummary struct {
+	UserID       string   `json:"user_id"`
+	Username     string   `json:"username"`
+	OpenCases    int      `json:"open_cases"`
+	MaxOpenCases int      `json:"max_open_cases"`
+	Territories  []string `json:"territories"`
+	Assigned     int      `json:"assigned"`
+}
+
+// AgentAssignmentPlan is the outcome of a dry run of agent auto-assignment
+type AgentAssignmentPlan struct {
+	Strategy    string                   `json:"strategy"`
+	Assignments []AgentAssignment        `json:"assignments"`
+	Unassigned  []AllocationSkip         `json:"unassigned"`
+	Agents      []AgentAssignmentSummary `json:"agents"`
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// AllocationRule decides which unallocated cases an agency may receive from
+// the allocation engine. Empty lists match every case. Agencies without an
+// active rule receive no cases.
+type AllocationRule struct {
+	ID         string                      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	AgencyID   string                      `gorm:"type:uuid;not null;unique;column:agency_id" json:"agency_id"`
+	DPDBuckets datatypes.JSONSlice[string] `gorm:"type:jsonb;not null;default:'[]';column:dpd_buckets" json:"dpd_buckets"`
+	LoanTypes  datatypes.JSONSlice[string] `gorm:"type:jsonb;not null;default:'[]';column:loan_types" json:"loan_types"`
+	Pincodes   datatypes.JSONSlice[string] `gorm:"type:jsonb;not null;default:'[]';column:pincodes" json:"pincodes"`
+	Capacity   *int                        `gorm:"type:integer;column:capacity" json:"capacity"`
+	Weight     float64                     `gorm:"type:numeric(10,2);not null;default:1;column:weight" json:"weight"`
+	IsActive   bool                        `gorm:"type:boolean;not null;default:true;column:is_active" json:"is_active"`
+	CreatedAt  time.Time                   `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;column:created_at" json:"created_at"`
+	UpdatedAt  time.Time                   `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;column:updated_at" json:"updated_at"`
+}
+
+func (AllocationRule) TableName() string {
+	return "agency_allocation_rules"
+}
+
+// AllocationCase is an unallocated case with the fields the rules match on
+type AllocationCase struct {
+	ID              string
+	LoanID          string
+	DPDBucket       string
+	LoanDescription string
+	Pincode         string
+	Outstanding     float64
+}
+
+// AgencyAllocationStats holds the load and recent collections of an agency
+type AgencyAllocationStats struct {
+	AgencyID    string
+	OpenCases   int
+	Collected   float64
+	Outstanding float64
+}
+
+type AllocationAssignment struct {
+	CaseID     string `json:"case_id" binding:"required"`
+	LoanID     string `json:"loan_id,omitempty"`
+	AgencyID   string `json:"agency_id" binding:"required"`
+	AgencyName string `json:"agency_name,omitempty"`
+}
+
+type AllocationSkip struct {
+	CaseID string `json:"case_id"`
+	LoanID string `json:"loan_id"`
+	Reason string `json:"reason"`
+}
+
+type AgencyAllocationSummary struct {
+	AgencyID          string  `json:"agency_id"`
+	AgencyName        string  `json:"agency_name"`
+	OpenCases         int     `json:"open_cases"`
+	Capacity          *int    `json:"capacity"`
+	Weight            float64 `json:"weight"`
+	PerformanceFactor float64 `json:"performance_factor"`
+	Allocated         int     `json:"allocated"`
+}
+
+// AllocationPlan is the outcome of a dry run of the allocation engine
+type AllocationPlan struct {
+	Assignments []AllocationAssignment    `json:"assignments"`
+	Unallocated []AllocationSkip          `json:"unallocated"`
+	Agencies    []AgencyAllocationSummary `json:"agencies"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// AuditEvent records one mutating API call. Rows are never updated or
+// deleted. EntityType and EntityID default to the resource in the path;
+// Before and After are filled in by the services that know the entity.
+type AuditEvent struct {
+	ID            string         `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ActorID       *string        `gorm:"type:uuid" json:"actor_id"`
+	ActorUsername string         `gorm:"type:varchar(50)" json:"actor_username"`
+	Action        string         `gorm:"type:varchar(255);not null" json:"action"`
+	EntityType    string         `gorm:"type:varchar(50)" json:"entity_type"`
+	EntityID      string         `gorm:"type:varchar(100)" json:"entity_id"`
+	Before        datatypes.JSON `gorm:"type:jsonb" json:"before"`
+	After         datatypes.JSON `gorm:"type:jsonb" json:"after"`
+	StatusCode    int            `gorm:"type:int;not null" json:"status_code"`
+	IPAddress     string         `gorm:"type:varchar(64)" json:"ip_address"`
+	RequestID     string         `gorm:"type:varchar(64)" json:"request_id"`
+	CreatedAt     time.Time      `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+// AuditEventFilter narrows the audit log listing. Unset fields match every
+// event.
+type AuditEventFilter struct {
+	ActorID    *string
+	Action     *string
+	EntityType *string
+	EntityID   *string
+	RequestID  *string
+	From       *time.Time
+	To         *time.Time
+	Page       int
+	PageSize   int
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// AuthEvent records a security relevant login event such as a lockout
+type AuthEvent struct {
+	ID          string     `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	EventType   string     `gorm:"type:varchar(50);not null"`
+	Username    string     `gorm:"type:varchar(50)"`
+	UserID      *string    `gorm:"type:uuid"`
+	IPAddress   string     `gorm:"type:varchar(64)"`
+	Attempts    int        `gorm:"type:int;not null;default:0"`
+	LockedUntil *time.Time `gorm:"type:timestamp"`
+	ActorID     *string    `gorm:"type:uuid"`
+	CreatedAt   time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (AuthEvent) TableName() string {
+	return "auth_events"
+}
@@ -22,6 +22,7 @@ type Case struct {
 	EMIsPending            int       `gorm:"type:integer;column:emis_pending"`
 	BounceCharges          float64   `gorm:"type:numeric(10,2);column:bounce_charges"`
 	NachPresentationStatus string    `gorm:"type:varchar(50);column:nach_presentation_status"`
+	LenderID               *string   `gorm:"type:uuid;column:lender_id"`
 	CreatedAt              time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;column:created_at"`
 	UpdatedAt              time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;column:updated_at"`
 }
@@ -29,3 +30,32 @@ type Case struct {
 func (Case) TableName() string {
 	return "cases"
 }
+
+// CaseWithAssignee is a case listed together with its assigned agent, if any
+type CaseWithAssignee struct {
+	Case
+	AssigneeID       *string `gorm:"column:assignee_id"`
+	AssigneeUsername *string `gorm:"column:assignee_username"`
+	AssigneeEmail    *string `gorm:"column:assignee_email"`
+}
+
+// CaseFilter narrows and orders a case listing. Nil and empty fields do not
+// filter. Outstanding is principal plus interest outstanding.
+type CaseFilter struct {
+	AgencyID           *string
+	AssignedUserID     *string
+	UnassignedToAgency bool
+	DPDBuckets         []string
+	DPDMin             *int
+	DPDMax             *int
+	CaseStatuses       []string
+	NachStatuses       []string
+	EMIDateFrom        *time.Time
+	EMIDateTo          *time.Time
+	OutstandingMin     *float64
+	OutstandingMax     *float64
+	SortBy             string
+	SortDescending     bool
+	Page               int
+	PageSize           int
+}
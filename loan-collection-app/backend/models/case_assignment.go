@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// CaseAssignmentRecord is one agency or agent a case has been with
+type CaseAssignmentRecord struct {
+	Level                string     `json:"level"`
+	AssigneeID           string     `json:"assignee_id"`
+	AssigneeName         *string    `json:"assignee_name"`
+	AssignedAt           time.Time  `json:"assigned_at"`
+	AssignedBy           *string    `json:"assigned_by"`
+	AssignedByUsername   *string    `json:"assigned_by_username"`
+	UnassignedAt         *time.Time `json:"unassigned_at"`
+	UnassignedBy         *string    `json:"unassigned_by"`
+	UnassignedByUsername *string    `json:"unassigned_by_username"`
+	UnassignReason       *string    `json:"unassign_reason"`
+	UnassignRemarks      *string    `json:"unassign_remarks"`
+}
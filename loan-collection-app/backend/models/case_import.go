@@ -0,0 +1,21 @@
+package models
+
+// ImportRowError describes why a row of an upload was rejected
+type ImportRowError struct {
+	Row    int    `json:"row"`
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+type ImportReport struct {
+	Mode      string           `json:"mode"`
+	TotalRows int              `json:"total_rows"`
+	Imported  int              `json:"imported"`
+	Created   int              `json:"created"`
+	Updated   int              `json:"updated"`
+	Unchanged int              `json:"unchanged"`
+	Rejected  int              `json:"rejected"`
+	Errors    []ImportRowError `json:"errors"`
+	// ErrorsTruncated is set when more row errors occurred than are reported
+	ErrorsTruncated bool `json:"errors_truncated"`
+}
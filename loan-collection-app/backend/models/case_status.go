@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+)
+
+// CaseStatusChange records one move of a case through its lifecycle. ActorID
+// is nil for changes made by the system, such as payments.
+type CaseStatusChange struct {
+	ID         string    `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	CaseID     string    `gorm:"type:uuid;not null;column:case_id"`
+	FromStatus string    `gorm:"type:varchar(50);not null;column:from_status"`
+	ToStatus   string    `gorm:"type:varchar(50);not null;column:to_status"`
+	ReasonCode string    `gorm:"type:varchar(50);not null;column:reason_code"`
+	Remarks    string    `gorm:"type:text;column:remarks"`
+	ActorID    *string   `gorm:"type:uuid;column:actor_id"`
+	CreatedAt  time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;column:created_at"`
+}
+
+func (CaseStatusChange) TableName() string {
+	return "case_status_history"
+}
+
+// CaseStatusChangeDetails is a status change joined with the user who made it
+type CaseStatusChangeDetails struct {
+	ID            string    `json:"id"`
+	CaseID        string    `json:"case_id"`
+	FromStatus    string    `json:"from_status"`
+	ToStatus      string    `json:"to_status"`
+	ReasonCode    string    `json:"reason_code"`
+	Remarks       string    `json:"remarks"`
+	ActorID       *string   `json:"actor_id"`
+	ActorUsername *string   `json:"actor_username"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+type Customer struct {
+	ID                 string                               `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	ExternalCustomerID string                               `gorm:"type:varchar(50);not null;unique;column:external_customer_id"`
+	Name               string                               `gorm:"type:varchar(255);column:name"`
+	Email              *string                              `gorm:"type:varchar(100);column:email"`
+	Phones             datatypes.JSONSlice[string]          `gorm:"type:jsonb;not null;default:'[]';column:phones"`
+	Addresses          datatypes.JSONSlice[CustomerAddress] `gorm:"type:jsonb;not null;default:'[]';column:addresses"`
+	Pincode            string                               `gorm:"type:varchar(10);column:pincode"`
+	CreatedAt          time.Time                            `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;column:created_at"`
+	UpdatedAt          time.Time                            `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;column:updated_at"`
+}
+
+func (Customer) TableName() string {
+	return "customers"
+}
+
+type CustomerAddress struct {
+	Address string `json:"address"`
+	City    string `json:"city"`
+	State   string `json:"state"`
+	Pincode string `json:"pincode"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// DPDBucket is one row of the bucket table. A case falls in the first bucket
+// whose MaxDPD is at least its DPD; a nil MaxDPD has no upper limit.
+type DPDBucket struct {
+	Name   string `mapstructure:"name"`
+	MaxDPD *int   `mapstructure:"max_dpd"`
+}
+
+// DPDSnapshot keeps the daily DPD position of a case for trend reporting
+type DPDSnapshot struct {
+	ID                   string    `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	SnapshotDate         time.Time `gorm:"type:date;not null;column:snapshot_date"`
+	CaseID               string    `gorm:"type:uuid;not null;column:case_id"`
+	LenderID             *string   `gorm:"type:uuid;column:lender_id"`
+	AgencyID             *string   `gorm:"type:uuid;column:agency_id"`
+	CaseStatus           string    `gorm:"type:varchar(50);column:case_status"`
+	DPD                  int       `gorm:"type:integer;column:dpd"`
+	DPDBucket            string    `gorm:"type:varchar(50);column:dpd_bucket"`
+	PrincipalOutstanding float64   `gorm:"type:numeric(10,2);column:principal_outstanding"`
+	InterestOutstanding  float64   `gorm:"type:numeric(10,2);column:interest_outstanding"`
+	CreatedAt            time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;column:created_at"`
+}
+
+func (DPDSnapshot) TableName() string {
+	return "dpd_snapshots"
+}
+
+// DPDRecomputeResult summarises one run of the DPD job
+type DPDRecomputeResult struct {
+	AsOf        time.Time
+	Processed   int
+	Updated     int
+	Snapshotted int
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// ImportJob tracks a case upload processed in the background
+type ImportJob struct {
+	ID         string                           `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	FileName   string                           `gorm:"type:varchar(255);not null;column:file_name"`
+	FileSize   int64                            `gorm:"type:bigint;not null;column:file_size"`
+	Mode       string                           `gorm:"type:varchar(20);not null;column:mode"`
+	Status     string                           `gorm:"type:varchar(20);not null;column:status"`
+	CreatedBy  string                           `gorm:"type:uuid;not null;column:created_by"`
+	BytesRead  int64                            `gorm:"type:bigint;not null;default:0;column:bytes_read"`
+	Report     datatypes.JSONType[ImportReport] `gorm:"type:jsonb;column:report"`
+	Error      string                           `gorm:"type:text;column:error"`
+	StartedAt  *time.Time                       `gorm:"type:timestamp;column:started_at"`
+	FinishedAt *time.Time                       `gorm:"type:timestamp;column:finished_at"`
+	CreatedAt  time.Time                        `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;column:created_at"`
+	UpdatedAt  time.Time                        `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;column:updated_at"`
+}
+
+func (ImportJob) TableName() string {
+	return "import_jobs"
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+type Lender struct {
+	ID         string    `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	LenderName string    `gorm:"type:varchar(255);not null"`
+	Status     string    `gorm:"type:varchar(50);not null"`
+	CreatedAt  time.Time `gorm:"type:timestamp;not null"`
+	UpdatedAt  time.Time `gorm:"type:timestamp;not null"`
+}
+
+type LenderUserMap struct {
+	ID         string    `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	LenderID   string    `gorm:"type:uuid;not null"`
+	UserID     string    `gorm:"type:uuid;not null"`
+	IsActive   bool      `gorm:"type:boolean;not null"`
+	AssignedAt time.Time `gorm:"type:timestamp;not null"`
+	UpdatedAt  time.Time `gorm:"type:timestamp;not null"`
+}
+
+func (LenderUserMap) TableName() string {
+	return "lender_user_map"
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+type PaymentLink struct {
+	ID                string    `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	CaseID            string    `gorm:"type:uuid;not null;column:case_id"`
+	CreatedBy         string    `gorm:"type:uuid;not null;column:created_by"`
+	AmountType        string    `gorm:"type:varchar(20);not null;column:amount_type"`
+	Amount            float64   `gorm:"type:numeric(10,2);not null;column:amount"`
+	Provider          string    `gorm:"type:varchar(50);not null;column:provider"`
+	ProviderReference string    `gorm:"type:varchar(255);column:provider_reference"`
+	URL               string    `gorm:"type:text;column:url"`
+	Status            string    `gorm:"type:varchar(20);not null;column:status"`
+	ExpiresAt         time.Time `gorm:"type:timestamp;not null;column:expires_at"`
+	CreatedAt         time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;column:created_at"`
+	UpdatedAt         time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;column:updated_at"`
+}
+
+func (PaymentLink) TableName() string {
+	return "payment_links"
+}
+
+type Payment struct {
+	ID               string    `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	CaseID           string    `gorm:"type:uuid;not null;column:case_id"`
+	PaymentLinkID    *string   `gorm:"type:uuid;column:payment_link_id"`
+	Source           string    `gorm:"type:varchar(50);not null;column:source"`
+	EventID          *string   `gorm:"type:varchar(255);unique;column:event_id"`
+	Mode             string    `gorm:"type:varchar(50);column:mode"`
+	PaymentReference string    `gorm:"type:varchar(255);column:payment_reference"`
+	Amount           float64   `gorm:"type:numeric(10,2);not null;column:amount"`
+	PaidAt           time.Time `gorm:"type:timestamp;not null;column:paid_at"`
+	CreatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;column:created_at"`
+}
+
+func (Payment) TableName() string {
+	return "payments"
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// PaymentReconciliation is one lender payment file matched against the
+// cases and posted
+type PaymentReconciliation struct {
+	ID        string                                    `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	FileName  string                                    `gorm:"type:varchar(255);not null;column:file_name" json:"file_name"`
+	LenderID  *string                                   `gorm:"type:uuid;column:lender_id" json:"lender_id"`
+	CreatedBy string                                    `gorm:"type:uuid;not null;column:created_by" json:"created_by"`
+	Summary   datatypes.JSONType[ReconciliationSummary] `gorm:"type:jsonb;not null;column:summary" json:"summary"`
+	CreatedAt time.Time                                 `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;column:created_at" json:"created_at"`
+}
+
+func (PaymentReconciliation) TableName() string {
+	return "payment_reconciliations"
+}
+
+// ReconciliationSummary counts the lines of a payment file and totals their
+// amounts by outcome. Possible duplicates are posted and counted in Posted
+// as well.
+type ReconciliationSummary struct {
+	TotalLines        int     `json:"total_lines"`
+	TotalAmount       float64 `json:"total_amount"`
+	Posted            int     `json:"posted"`
+	PostedAmount      float64 `json:"posted_amount"`
+	PossibleDuplicate int     `json:"possible_duplicate"`
+	Unmatched         int     `json:"unmatched"`
+	UnmatchedAmount   float64 `json:"unmatched_amount"`
+	Duplicate         int     `json:"duplicate"`
+	DuplicateAmount   float64 `json:"duplicate_amount"`
+	Invalid           int     `json:"invalid"`
+}
+
+// PaymentReconciliationLine is the outcome of one line of a payment file.
+// CaseID is set once the loan is matched and PaymentID once it is posted.
+type PaymentReconciliationLine struct {
+	ID               string     `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ReconciliationID string     `gorm:"type:uuid;not null;column:reconciliation_id" json:"-"`
+	Row              int        `gorm:"type:integer;not null;column:row_number" json:"row"`
+	LoanID           string     `gorm:"type:varchar(50);column:loan_id" json:"loan_id"`
+	Amount           float64    `gorm:"type:numeric(10,2);column:amount" json:"amount"`
+	PaidAt           *time.Time `gorm:"type:timestamp;column:paid_at" json:"paid_at"`
+	Mode             string     `gorm:"type:varchar(50);column:mode" json:"mode"`
+	Reference        string     `gorm:"type:varchar(255);column:reference" json:"reference"`
+	Status           string     `gorm:"type:varchar(20);not null;column:status" json:"status"`
+	Reason           string     `gorm:"type:text;column:reason" json:"reason"`
+	CaseID           *string    `gorm:"type:uuid;column:case_id" json:"case_id"`
+	PaymentID        *string    `gorm:"type:uuid;column:payment_id" json:"payment_id"`
+}
+
+func (PaymentReconciliationLine) TableName() string {
+	return "payment_reconciliation_lines"
+}
+
+// ReconciliationLineFilter selects a page of the lines of a reconciliation
+type ReconciliationLineFilter struct {
+	Status   *string
+	Page     int
+	PageSize int
+}
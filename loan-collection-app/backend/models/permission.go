@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+type Permission struct {
+	ID          string    `gorm:"column:id;primaryKey;autoIncrement"`
+	Name        string    `gorm:"column:name;unique;not null"`
+	Description string    `gorm:"column:description"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+type RolePermission struct {
+	RoleID       string    `gorm:"column:role_id;primaryKey"`
+	PermissionID string    `gorm:"column:permission_id;primaryKey"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+func (RolePermission) TableName() string {
+	return "role_permissions"
+}
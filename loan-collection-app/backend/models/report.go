@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+)
+
+// CollectionPerformanceFilter selects the period, grouping and scope of a
+// collection performance report. To is exclusive.
+type CollectionPerformanceFilter struct {
+	From     time.Time
+	To       time.Time
+	GroupBy  string
+	LenderID *string
+	AgencyID *string
+}
+
+// CollectionPerformanceRow holds the performance of one group. Counts and
+// amounts come from the database; rates are derived from them.
+type CollectionPerformanceRow struct {
+	GroupID              string  `json:"group_id"`
+	GroupName            string  `json:"group_name"`
+	Cases                int     `json:"cases"`
+	AllocatedAmount      float64 `json:"allocated_amount"`
+	CollectedAmount      float64 `json:"collected_amount"`
+	ResolvedCases        int     `json:"resolved_cases"`
+	ContactedCases       int     `json:"contacted_cases"`
+	Trails               int     `json:"trails"`
+	PTPsDue              int     `json:"ptps_due" gorm:"column:ptps_due"`
+	PTPsKept             int     `json:"ptps_kept" gorm:"column:ptps_kept"`
+	CollectionEfficiency float64 `json:"collection_efficiency" gorm:"-"`
+	ResolutionRate       float64 `json:"resolution_rate" gorm:"-"`
+	ContactRate          float64 `json:"contact_rate" gorm:"-"`
+	PTPKeptRate          float64 `json:"ptp_kept_rate" gorm:"-"`
+	AverageTrailsPerCase float64 `json:"average_trails_per_case" gorm:"-"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+type Trail struct {
+	ID           string     `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	CaseID       string     `gorm:"type:uuid;not null;column:case_id"`
+	UserID       string     `gorm:"type:uuid;not null;column:user_id"`
+	Disposition  string     `gorm:"type:varchar(50);not null;column:disposition"`
+	PaymentDate  *time.Time `gorm:"type:date;column:payment_date"`
+	FollowUpDate *time.Time `gorm:"type:date;column:follow_up_date"`
+	Remarks      string     `gorm:"type:text;column:remarks"`
+	CreatedAt    time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;column:created_at"`
+}
+
+func (Trail) TableName() string {
+	return "trails"
+}
+
+// TrailDetails is a trail joined with the agent who recorded it
+type TrailDetails struct {
+	ID           string     `json:"id"`
+	CaseID       string     `json:"case_id"`
+	UserID       string     `json:"user_id"`
+	Username     string     `json:"username"`
+	Disposition  string     `json:"disposition"`
+	PaymentDate  *time.Time `json:"payment_date"`
+	FollowUpDate *time.Time `json:"follow_up_date"`
+	Remarks      string     `json:"remarks"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
@@ -18,6 +18,12 @@ type User struct {
 	AgencyID     *string         `gorm:"-"`
 }
 
+// UserWithRoles is a user listed together with the names of their active roles
+type UserWithRoles struct {
+	User
+	RoleNames datatypes.JSONSlice[string] `gorm:"column:role_names"`
+}
+
 // TableName sets the table name for the User model
 func (User) TableName() string {
 	return "users"
@@ -0,0 +1,77 @@
+package payments
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+// FakeProvider issues links locally without calling any gateway, for dev and test
+type FakeProvider struct {
+	baseURL string
+}
+
+func NewFakeProvider() *FakeProvider {
+	baseURL := viper.GetString("PAYMENT_FAKE_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080/fake-pay"
+	}
+	return &FakeProvider{baseURL: baseURL}
+}
+
+func (p *FakeProvider) Name() string {
+	return PROVIDER_FAKE
+}
+
+func (p *FakeProvider) CreateLink(req LinkRequest) (*Link, error) {
+	reference := "fake_" + uuid.NewString()
+	return &Link{
+		ProviderReference: reference,
+		URL:               fmt.Sprintf("%s/%s?amount=%.2f", p.baseURL, reference, req.Amount),
+		Status:            "created",
+	}, nil
+}
+
+type fakeWebhookPayload struct {
+	EventID          string  `json:"event_id"`
+	Event            string  `json:"event"`
+	ReferenceID      string  `json:"reference_id"`
+	LinkReference    string  `json:"link_reference"`
+	PaymentReference string  `json:"payment_reference"`
+	Mode             string  `json:"mode"`
+	Amount           float64 `json:"amount"`
+}
+
+// ParseWebhook accepts a simple JSON payload signed in X-Fake-Signature,
+// which lets dev and test environments simulate gateway callbacks
+func (p *FakeProvider) ParseWebhook(headers http.Header, body []byte) (*WebhookEvent, error) {
+	err := verifySignature(body, headers.Get("X-Fake-Signature"), viper.GetString("PAYMENT_WEBHOOK_SECRET"))
+	if err != nil {
+		return nil, err
+	}
+
+	var payload fakeWebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	if payload.EventID == "" {
+		return nil, errors.New("event_id is required")
+	}
+
+	return &WebhookEvent{
+		EventID:           payload.EventID,
+		Type:              strings.ToUpper(payload.Event),
+		ReferenceID:       payload.ReferenceID,
+		ProviderReference: payload.LinkReference,
+		PaymentReference:  payload.PaymentReference,
+		Mode:              payload.Mode,
+		Amount:            payload.Amount,
+		PaidAt:            time.Now(),
+	}, nil
+}
@@ -0,0 +1,54 @@
+package payments
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	PROVIDER_FAKE     = "fake"
+	PROVIDER_RAZORPAY = "razorpay"
+)
+
+// LinkRequest describes the payment link to be created with a provider
+type LinkRequest struct {
+	ReferenceID   string
+	Amount        float64
+	Currency      string
+	Description   string
+	ExpiresAt     time.Time
+	CustomerName  string
+	CustomerPhone string
+	CustomerEmail string
+}
+
+// Link is the provider's view of a created payment link
+type Link struct {
+	ProviderReference string
+	URL               string
+	Status            string
+}
+
+// PaymentProvider creates payment links with a payment gateway and parses
+// the signed notifications it sends back
+type PaymentProvider interface {
+	Name() string
+	CreateLink(req LinkRequest) (*Link, error)
+	ParseWebhook(headers http.Header, body []byte) (*WebhookEvent, error)
+}
+
+// NewProvider returns the provider selected by PAYMENT_PROVIDER
+func NewProvider(httpClient *http.Client) (PaymentProvider, error) {
+	provider := viper.GetString("PAYMENT_PROVIDER")
+	switch provider {
+	case PROVIDER_RAZORPAY:
+		return NewRazorpayProvider(httpClient), nil
+	case PROVIDER_FAKE, "":
+		return NewFakeProvider(), nil
+	default:
+		return nil, fmt.Errorf("unsupported payment provider: %s", provider)
+	}
+}
@@ -0,0 +1,175 @@
+package payments
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// RazorpayProvider creates links through the Razorpay payment links API
+type RazorpayProvider struct {
+	httpClient *http.Client
+	baseURL    string
+	keyID      string
+	keySecret  string
+}
+
+func NewRazorpayProvider(httpClient *http.Client) *RazorpayProvider {
+	baseURL := viper.GetString("RAZORPAY_BASE_URL")
+	if baseURL == "" {
+		baseURL = "https://api.razorpay.com/v1"
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &RazorpayProvider{
+		httpClient: httpClient,
+		baseURL:    baseURL,
+		keyID:      viper.GetString("RAZORPAY_KEY_ID"),
+		keySecret:  viper.GetString("RAZORPAY_KEY_SECRET"),
+	}
+}
+
+func (p *RazorpayProvider) Name() string {
+	return PROVIDER_RAZORPAY
+}
+
+type razorpayCustomer struct {
+	Name    string `json:"name,omitempty"`
+	Contact string `json:"contact,omitempty"`
+	Email   string `json:"email,omitempty"`
+}
+
+type razorpayLinkRequest struct {
+	Amount      int64             `json:"amount"`
+	Currency    string            `json:"currency"`
+	ExpireBy    int64             `json:"expire_by"`
+	ReferenceID string            `json:"reference_id"`
+	Description string            `json:"description"`
+	Customer    *razorpayCustomer `json:"customer,omitempty"`
+}
+
+type razorpayLinkResponse struct {
+	ID       string `json:"id"`
+	ShortURL string `json:"short_url"`
+	Status   string `json:"status"`
+}
+
+func (p *RazorpayProvider) CreateLink(req LinkRequest) (*Link, error) {
+	if p.keyID == "" || p.keySecret == "" {
+		return nil, fmt.Errorf("razorpay credentials are not configured")
+	}
+
+	body := razorpayLinkRequest{
+		// Razorpay expects amounts in the smallest currency unit
+		Amount:      int64(math.Round(req.Amount * 100)),
+		Currency:    req.Currency,
+		ExpireBy:    req.ExpiresAt.Unix(),
+		ReferenceID: req.ReferenceID,
+		Description: req.Description,
+	}
+	if req.CustomerName != "" || req.CustomerPhone != "" || req.CustomerEmail != "" {
+		body.Customer = &razorpayCustomer{
+			Name:    req.CustomerName,
+			Contact: req.CustomerPhone,
+			Email:   req.CustomerEmail,
+		}
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, p.baseURL+"/payment_links", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.SetBasicAuth(p.keyID, p.keySecret)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("razorpay returned status %d", resp.StatusCode)
+	}
+
+	var linkResp razorpayLinkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&linkResp); err != nil {
+		return nil, err
+	}
+
+	return &Link{
+		ProviderReference: linkResp.ID,
+		URL:               linkResp.ShortURL,
+		Status:            linkResp.Status,
+	}, nil
+}
+
+type razorpayWebhookPayload struct {
+	Event   string `json:"event"`
+	Payload struct {
+		PaymentLink struct {
+			Entity struct {
+				ID          string `json:"id"`
+				ReferenceID string `json:"reference_id"`
+			} `json:"entity"`
+		} `json:"payment_link"`
+		Payment struct {
+			Entity struct {
+				ID        string `json:"id"`
+				Amount    int64  `json:"amount"`
+				Method    string `json:"method"`
+				CreatedAt int64  `json:"created_at"`
+			} `json:"entity"`
+		} `json:"payment"`
+	} `json:"payload"`
+}
+
+func (p *RazorpayProvider) ParseWebhook(headers http.Header, body []byte) (*WebhookEvent, error) {
+	err := verifySignature(body, headers.Get("X-Razorpay-Signature"), viper.GetString("PAYMENT_WEBHOOK_SECRET"))
+	if err != nil {
+		return nil, err
+	}
+
+	eventID := headers.Get("X-Razorpay-Event-Id")
+	if eventID == "" {
+		return nil, fmt.Errorf("missing razorpay event id")
+	}
+
+	var payload razorpayWebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	event := &WebhookEvent{
+		EventID:           eventID,
+		ReferenceID:       payload.Payload.PaymentLink.Entity.ReferenceID,
+		ProviderReference: payload.Payload.PaymentLink.Entity.ID,
+		PaymentReference:  payload.Payload.Payment.Entity.ID,
+		Mode:              payload.Payload.Payment.Entity.Method,
+		Amount:            float64(payload.Payload.Payment.Entity.Amount) / 100,
+		PaidAt:            time.Unix(payload.Payload.Payment.Entity.CreatedAt, 0),
+	}
+	switch payload.Event {
+	case "payment_link.paid":
+		event.Type = WEBHOOK_EVENT_PAID
+	case "payment_link.partially_paid":
+		event.Type = WEBHOOK_EVENT_PARTIALLY_PAID
+	case "payment_link.expired":
+		event.Type = WEBHOOK_EVENT_EXPIRED
+	case "payment_link.cancelled":
+		event.Type = WEBHOOK_EVENT_CANCELLED
+	default:
+		event.Type = WEBHOOK_EVENT_IGNORED
+	}
+	return event, nil
+}
@@ -0,0 +1,48 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+const (
+	WEBHOOK_EVENT_PAID           = "PAID"
+	WEBHOOK_EVENT_PARTIALLY_PAID = "PARTIALLY_PAID"
+	WEBHOOK_EVENT_EXPIRED        = "EXPIRED"
+	WEBHOOK_EVENT_CANCELLED      = "CANCELLED"
+	WEBHOOK_EVENT_IGNORED        = "IGNORED"
+)
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// WebhookEvent is a provider notification normalised across providers
+type WebhookEvent struct {
+	EventID           string
+	Type              string
+	ReferenceID       string // our payment link ID
+	ProviderReference string
+	PaymentReference  string
+	Mode              string
+	Amount            float64
+	PaidAt            time.Time
+}
+
+// verifySignature checks a hex encoded HMAC-SHA256 of the raw body
+func verifySignature(body []byte, signature, secret string) error {
+	if secret == "" {
+		return errors.New("webhook secret is not configured")
+	}
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -1,9 +1,12 @@
 package repository
 
 import (
+	"backend/constants"
 	"backend/models"
 	"errors"
+	"time"
 
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -24,6 +27,18 @@ func (r *AgencyRepository) ListAllAgencies() ([]models.Agency, error) {
 	return agencies, nil
 }
 
+// GetAgency returns the agency with the given ID, or nil when there is none
+func (r *AgencyRepository) GetAgency(agencyID string) (*models.Agency, error) {
+	var agency models.Agency
+	if err := r.db.Where("id = ?", agencyID).Take(&agency).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &agency, nil
+}
+
 func (r *AgencyRepository) CreateAgency(agency *models.Agency) error {
 	if agency.Status == "" {
 		agency.Status = "ACTIVE"
@@ -56,6 +71,9 @@ func (r *AgencyRepository) AssignUserToAgency(mapping *models.AgencyUserMap) err
 		return err
 	}
 
+	if mapping.Territories == nil {
+		mapping.Territories = []string{}
+	}
 	result := r.db.Table("agency_user_map").Create(mapping)
 	return result.Error
 }
@@ -88,23 +106,18 @@ func (r *AgencyRepository) CreateAgencyCaseMap(agencyCaseMapList []models.Agency
 	return result.Error
 }
 
-func (r *AgencyRepository) ListCases(agencyID string) ([]models.Case, error) {
-	cases := []models.Case{}
-	result := r.db.Table("cases").
-		Joins("JOIN agency_case_map ON cases.id = agency_case_map.case_id").
-		Where("agency_case_map.agency_id = ?", agencyID).
-		Find(&cases)
-	return cases, result.Error
+func (r *AgencyRepository) agencyUsers(agencyID string) *gorm.DB {
+	return r.db.Table("agency_user_map").
+		Select("users.id as user_id, users.username, users.email, agency_user_map.agency_role, agency_user_map.manager_id, users.is_active, agency_user_map.max_open_cases, agency_user_map.territories").
+		Joins("JOIN users ON users.id = agency_user_map.user_id").
+		Where("agency_user_map.agency_id = ? AND agency_user_map.is_active = true", agencyID).
+		Order("users.username")
 }
 
 func (r *AgencyRepository) ListAgencyUsers(agencyID string) ([]models.AgencyUserDetails, error) {
 	var users []models.AgencyUserDetails
 
-	result := r.db.Table("agency_user_map").
-		Select("users.id as user_id, users.username, users.email, agency_user_map.agency_role, agency_user_map.manager_id").
-		Joins("JOIN users ON users.id = agency_user_map.user_id").
-		Where("agency_user_map.agency_id = ? AND agency_user_map.is_active = true", agencyID).
-		Scan(&users)
+	result := r.agencyUsers(agencyID).Scan(&users)
 
 	if result.Error != nil {
 		return nil, result.Error
@@ -113,6 +126,21 @@ func (r *AgencyRepository) ListAgencyUsers(agencyID string) ([]models.AgencyUser
 	return users, nil
 }
 
+// ListAgencyUsersWithRole lists the active users of an agency that hold the
+// given role
+func (r *AgencyRepository) ListAgencyUsersWithRole(agencyID, roleName string) ([]models.AgencyUserDetails, error) {
+	users := []models.AgencyUserDetails{}
+	err := r.agencyUsers(agencyID).
+		Where("users.is_active = true").
+		Where(`EXISTS (
+			SELECT 1 FROM user_role_map
+			JOIN roles ON roles.id = user_role_map.role_id
+			WHERE user_role_map.user_id = users.id AND user_role_map.is_active = true AND roles.role_name = ?
+		)`, roleName).
+		Scan(&users).Error
+	return users, err
+}
+
 func (r *AgencyRepository) ListUnassignedUsers() ([]models.User, error) {
 	var users []models.User
 
@@ -129,3 +157,73 @@ func (r *AgencyRepository) ListUnassignedUsers() ([]models.User, error) {
 
 	return users, nil
 }
+
+// UpdateAgentAssignmentProfile sets the case cap and territories used when
+// cases are auto-assigned to an agent of the agency
+func (r *AgencyRepository) UpdateAgentAssignmentProfile(agencyID, userID string, maxOpenCases *int, territories []string) error {
+	result := r.db.Model(&models.AgencyUserMap{}).
+		Where("agency_id = ? AND user_id = ? AND is_active = true", agencyID, userID).
+		Updates(map[string]interface{}{
+			"max_open_cases": maxOpenCases,
+			"territories":    datatypes.JSONSlice[string](territories),
+			"updated_at":     time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListUnassignedAgencyCases returns the open cases allocated to the agency
+// that no agent holds yet, largest outstanding first. caseIDs narrows the
+// cases when set.
+func (r *AgencyRepository) ListUnassignedAgencyCases(agencyID string, caseIDs []string, limit int) ([]models.AllocationCase, error) {
+	query := r.db.Table("cases").
+		Select("cases.id, COALESCE(cases.loan_id, '') AS loan_id, COALESCE(cases.dpd_bucket, '') AS dpd_bucket, COALESCE(cases.loan_description, '') AS loan_description, COALESCE(customers.pincode, '') AS pincode, COALESCE(cases.principal_outstanding, 0) + COALESCE(cases.interest_outstanding, 0) AS outstanding").
+		Joins("JOIN agency_case_map ON agency_case_map.case_id = cases.id AND agency_case_map.unassigned_at IS NULL").
+		Joins("LEFT JOIN customers ON customers.external_customer_id = cases.external_customer_id").
+		Where("agency_case_map.agency_id = ?", agencyID).
+		Where("cases.case_status NOT IN ?", constants.ClosedCaseStatuses).
+		Where("NOT EXISTS (?)",
+			r.db.Table("case_user_map").
+				Select("1").
+				Where("case_user_map.case_id = cases.id AND case_user_map.unassigned_at IS NULL"))
+	if len(caseIDs) > 0 {
+		query = query.Where("cases.id IN ?", caseIDs)
+	}
+
+	cases := []models.AllocationCase{}
+	err := query.Order("outstanding DESC").Order("cases.id").Limit(limit).Scan(&cases).Error
+	return cases, err
+}
+
+// CountOpenCasesByUser returns how many open cases each of the users holds
+func (r *AgencyRepository) CountOpenCasesByUser(userIDs []string) (map[string]int, error) {
+	var rows []struct {
+		UserID    string
+		OpenCases int
+	}
+	err := r.db.Table("case_user_map").
+		Select("case_user_map.user_id, COUNT(*) AS open_cases").
+		Joins("JOIN cases ON cases.id = case_user_map.case_id").
+		Where("case_user_map.user_id IN ? AND case_user_map.unassigned_at IS NULL", userIDs).
+		Where("cases.case_status NOT IN ?", constants.ClosedCaseStatuses).
+		Group("case_user_map.user_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.UserID] = row.OpenCases
+	}
+	return counts, nil
+}
+
+func (r *AgencyRepository) CreateCaseUserMaps(mappings []models.CaseUserMap) error {
+	return r.db.CreateInBatches(mappings, 500).Error
+}
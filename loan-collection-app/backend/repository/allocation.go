@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"backend/constants"
+	"backend/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AllocationRepository struct {
+	db *gorm.DB
+}
+
+func NewAllocationRepository(db *gorm.DB) *AllocationRepository {
+	return &AllocationRepository{db: db}
+}
+
+func (r *AllocationRepository) ListRules() ([]models.AllocationRule, error) {
+	rules := []models.AllocationRule{}
+	err := r.db.Order("created_at").Find(&rules).Error
+	return rules, err
+}
+
+// ListActiveRules returns the active rules of active agencies
+func (r *AllocationRepository) ListActiveRules() ([]models.AllocationRule, error) {
+	rules := []models.AllocationRule{}
+	err := r.db.Joins("JOIN agencies ON agencies.id = agency_allocation_rules.agency_id").
+		Where("agency_allocation_rules.is_active = true AND agencies.status = ?", "ACTIVE").
+		Order("agency_allocation_rules.created_at").
+		Find(&rules).Error
+	return rules, err
+}
+
+// GetRuleByAgency returns the rule of an agency, or nil when it has none
+func (r *AllocationRepository) GetRuleByAgency(agencyID string) (*models.AllocationRule, error) {
+	var rule models.AllocationRule
+	if err := r.db.Where("agency_id = ?", agencyID).Take(&rule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// SaveRule creates or replaces the rule of an agency
+func (r *AllocationRepository) SaveRule(rule *models.AllocationRule) error {
+	rule.UpdatedAt = time.Now()
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "agency_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"dpd_buckets",
+			"loan_types",
+			"pincodes",
+			"capacity",
+			"weight",
+			"is_active",
+			"updated_at",
+		}),
+	}).Create(rule).Error
+}
+
+// ListUnallocatedCases returns open cases not allocated to any agency,
+// largest outstanding first. lenderID and caseIDs narrow the cases when set.
+func (r *AllocationRepository) ListUnallocatedCases(lenderID *string, caseIDs []string, limit int) ([]models.AllocationCase, error) {
+	query := r.db.Table("cases").
+		Select("cases.id, COALESCE(cases.loan_id, '') AS loan_id, COALESCE(cases.dpd_bucket, '') AS dpd_bucket, COALESCE(cases.loan_description, '') AS loan_description, COALESCE(customers.pincode, '') AS pincode, COALESCE(cases.principal_outstanding, 0) + COALESCE(cases.interest_outstanding, 0) AS outstanding").
+		Joins("LEFT JOIN customers ON customers.external_customer_id = cases.external_customer_id").
+		Where("cases.case_status NOT IN ?", constants.ClosedCaseStatuses).
+		Where("NOT EXISTS (?)",
+			r.db.Table("agency_case_map").
+				Select("1").
+				Where("agency_case_map.case_id = cases.id AND agency_case_map.unassigned_at IS NULL"))
+	if lenderID != nil {
+		query = query.Where("cases.lender_id = ?", *lenderID)
+	}
+	if len(caseIDs) > 0 {
+		query = query.Where("cases.id IN ?", caseIDs)
+	}
+
+	cases := []models.AllocationCase{}
+	err := query.Order("outstanding DESC").Order("cases.id").Limit(limit).Scan(&cases).Error
+	return cases, err
+}
+
+// GetAgencyStats returns, per agency, the open cases it holds, what it
+// collected on its cases since the given time and what is still outstanding
+func (r *AllocationRepository) GetAgencyStats(collectedSince time.Time) (map[string]models.AgencyAllocationStats, error) {
+	var rows []models.AgencyAllocationStats
+	err := r.db.Raw(`SELECT agency_case_map.agency_id,
+			COUNT(*) FILTER (WHERE cases.case_status NOT IN ?) AS open_cases,
+			COALESCE(SUM(collections.collected), 0) AS collected,
+			COALESCE(SUM(COALESCE(cases.principal_outstanding, 0) + COALESCE(cases.interest_outstanding, 0)), 0) AS outstanding
+		FROM agency_case_map
+		JOIN cases ON cases.id = agency_case_map.case_id AND agency_case_map.unassigned_at IS NULL
+		LEFT JOIN (
+			SELECT case_id, SUM(amount) AS collected
+			FROM payments
+			WHERE paid_at >= ?
+			GROUP BY case_id
+		) AS collections ON collections.case_id = cases.id
+		GROUP BY agency_case_map.agency_id`, constants.ClosedCaseStatuses, collectedSince).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]models.AgencyAllocationStats, len(rows))
+	for _, row := range rows {
+		stats[row.AgencyID] = row
+	}
+	return stats, nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"backend/models"
+
+	"gorm.io/gorm"
+)
+
+type AuditEventRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditEventRepository(db *gorm.DB) *AuditEventRepository {
+	return &AuditEventRepository{db: db}
+}
+
+func (r *AuditEventRepository) CreateAuditEvent(event *models.AuditEvent) error {
+	return r.db.Create(event).Error
+}
+
+// ListAuditEvents returns one page of the events matching filter, latest
+// first, and the number of matching events over all pages
+func (r *AuditEventRepository) ListAuditEvents(filter models.AuditEventFilter) ([]models.AuditEvent, int64, error) {
+	query := r.db.Model(&models.AuditEvent{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != nil {
+		query = query.Where("action = ?", *filter.Action)
+	}
+	if filter.EntityType != nil {
+		query = query.Where("entity_type = ?", *filter.EntityType)
+	}
+	if filter.EntityID != nil {
+		query = query.Where("entity_id = ?", *filter.EntityID)
+	}
+	if filter.RequestID != nil {
+		query = query.Where("request_id = ?", *filter.RequestID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	events := []models.AuditEvent{}
+	err := query.Order("created_at DESC").Order("id").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&events).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
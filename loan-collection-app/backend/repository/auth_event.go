@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"backend/models"
+
+	"gorm.io/gorm"
+)
+
+type AuthEventRepository struct {
+	db *gorm.DB
+}
+
+func NewAuthEventRepository(db *gorm.DB) *AuthEventRepository {
+	return &AuthEventRepository{db: db}
+}
+
+func (r *AuthEventRepository) CreateAuthEvent(event *models.AuthEvent) error {
+	return r.db.Create(event).Error
+}
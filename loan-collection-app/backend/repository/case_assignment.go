@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"backend/constants"
+	"backend/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CaseAssignmentRepository struct {
+	db *gorm.DB
+}
+
+func NewCaseAssignmentRepository(db *gorm.DB) *CaseAssignmentRepository {
+	return &CaseAssignmentRepository{db: db}
+}
+
+// GetActiveUserIDs returns the agent currently holding each case, keyed by
+// case ID
+func (r *CaseAssignmentRepository) GetActiveUserIDs(caseIDs []string) (map[string]string, error) {
+	var mappings []models.CaseUserMap
+	err := r.db.Table("case_user_map").
+		Select("case_id, user_id").
+		Where("case_id IN ? AND unassigned_at IS NULL", caseIDs).
+		Scan(&mappings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make(map[string]string, len(mappings))
+	for _, mapping := range mappings {
+		userIDs[mapping.CaseID] = mapping.UserID
+	}
+	return userIDs, nil
+}
+
+// EndAgencyMappings closes the current agency allocation of the cases
+func (r *CaseAssignmentRepository) EndAgencyMappings(caseIDs []string, actorID *string, reason, remarks string) error {
+	return r.endMappings(&models.AgencyCaseMap{}, caseIDs, actorID, reason, remarks)
+}
+
+// EndUserMappings closes the current agent assignment of the cases
+func (r *CaseAssignmentRepository) EndUserMappings(caseIDs []string, actorID *string, reason, remarks string) error {
+	return r.endMappings(&models.CaseUserMap{}, caseIDs, actorID, reason, remarks)
+}
+
+func (r *CaseAssignmentRepository) endMappings(model interface{}, caseIDs []string, actorID *string, reason, remarks string) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"unassigned_at":   now,
+		"unassigned_by":   actorID,
+		"unassign_reason": reason,
+		"updated_at":      now,
+	}
+	if remarks != "" {
+		updates["unassign_remarks"] = remarks
+	}
+	return r.db.Model(model).
+		Where("case_id IN ? AND unassigned_at IS NULL", caseIDs).
+		Updates(updates).Error
+}
+
+// ListAssignmentHistory returns every agency and agent a case has been with,
+// latest first
+func (r *CaseAssignmentRepository) ListAssignmentHistory(caseID string) ([]models.CaseAssignmentRecord, error) {
+	records := []models.CaseAssignmentRecord{}
+	err := r.db.Raw(`SELECT history.*, assigners.username AS assigned_by_username, unassigners.username AS unassigned_by_username
+		FROM (
+			SELECT CAST(? AS VARCHAR) AS level, agency_case_map.agency_id AS assignee_id, agencies.agency_name AS assignee_name,
+				agency_case_map.assigned_at, agency_case_map.assigned_by, agency_case_map.unassigned_at,
+				agency_case_map.unassigned_by, agency_case_map.unassign_reason, agency_case_map.unassign_remarks
+			FROM agency_case_map
+			LEFT JOIN agencies ON agencies.id = agency_case_map.agency_id
+			WHERE agency_case_map.case_id = ?
+			UNION ALL
+			SELECT CAST(? AS VARCHAR), case_user_map.user_id, users.username,
+				case_user_map.assigned_at, case_user_map.assigned_by, case_user_map.unassigned_at,
+				case_user_map.unassigned_by, case_user_map.unassign_reason, case_user_map.unassign_remarks
+			FROM case_user_map
+			LEFT JOIN users ON users.id = case_user_map.user_id
+			WHERE case_user_map.case_id = ?
+		) AS history
+		LEFT JOIN users AS assigners ON assigners.id = history.assigned_by
+		LEFT JOIN users AS unassigners ON unassigners.id = history.unassigned_by
+		ORDER BY history.assigned_at DESC, history.level`,
+		constants.ASSIGNMENT_LEVEL_AGENCY, caseID, constants.ASSIGNMENT_LEVEL_AGENT, caseID).
+		Scan(&records).Error
+	return records, err
+}
@@ -1,11 +1,13 @@
 package repository
 
 import (
+	"backend/constants"
 	"backend/models"
 	"errors"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CaseRepository struct {
@@ -24,50 +26,149 @@ func (r *CaseRepository) CreateCases(cases []models.Case) ([]models.Case, error)
 	return cases, nil
 }
 
-func (r *CaseRepository) GetUnassignedCases() ([]models.Case, error) {
-	var cases []models.Case
+const caseOutstandingExpr = "(cases.principal_outstanding + cases.interest_outstanding)"
 
-	err := r.db.Table("cases").
-		Select("cases.*").
-		Joins("LEFT JOIN agency_case_map ON cases.id = agency_case_map.case_id").
-		Where("agency_case_map.id IS NULL").
-		Find(&cases).Error
+var caseSortColumns = map[string]string{
+	constants.CASE_SORT_DPD:         "cases.dpd",
+	constants.CASE_SORT_EMI_DATE:    "cases.emi_date",
+	constants.CASE_SORT_OUTSTANDING: caseOutstandingExpr,
+	constants.CASE_SORT_LOAN_ID:     "cases.loan_id",
+	constants.CASE_SORT_CREATED_AT:  "cases.created_at",
+	constants.CASE_SORT_UPDATED_AT:  "cases.updated_at",
+}
 
-	if err != nil {
-		return nil, err
+// filterCases applies the scope and filters of a listing, without paging
+func (r *CaseRepository) filterCases(filter models.CaseFilter) *gorm.DB {
+	query := r.db.Model(&models.Case{})
+
+	if filter.AgencyID != nil {
+		query = query.Where("EXISTS (?)",
+			r.db.Table("agency_case_map").
+				Select("1").
+				Where("agency_case_map.case_id = cases.id AND agency_case_map.unassigned_at IS NULL AND agency_case_map.agency_id = ?", *filter.AgencyID))
+	}
+	if filter.UnassignedToAgency {
+		query = query.Where("NOT EXISTS (?)",
+			r.db.Table("agency_case_map").
+				Select("1").
+				Where("agency_case_map.case_id = cases.id AND agency_case_map.unassigned_at IS NULL"))
+	}
+	if filter.AssignedUserID != nil {
+		query = query.Where("EXISTS (?)",
+			r.db.Table("case_user_map").
+				Select("1").
+				Where("case_user_map.case_id = cases.id AND case_user_map.unassigned_at IS NULL AND case_user_map.user_id = ?", *filter.AssignedUserID))
+	}
+	if len(filter.DPDBuckets) > 0 {
+		query = query.Where("cases.dpd_bucket IN ?", filter.DPDBuckets)
+	}
+	if filter.DPDMin != nil {
+		query = query.Where("cases.dpd >= ?", *filter.DPDMin)
+	}
+	if filter.DPDMax != nil {
+		query = query.Where("cases.dpd <= ?", *filter.DPDMax)
 	}
+	if len(filter.CaseStatuses) > 0 {
+		query = query.Where("cases.case_status IN ?", filter.CaseStatuses)
+	}
+	if len(filter.NachStatuses) > 0 {
+		query = query.Where("cases.nach_presentation_status IN ?", filter.NachStatuses)
+	}
+	if filter.EMIDateFrom != nil {
+		query = query.Where("cases.emi_date >= ?", *filter.EMIDateFrom)
+	}
+	if filter.EMIDateTo != nil {
+		query = query.Where("cases.emi_date <= ?", *filter.EMIDateTo)
+	}
+	if filter.OutstandingMin != nil {
+		query = query.Where(caseOutstandingExpr+" >= ?", *filter.OutstandingMin)
+	}
+	if filter.OutstandingMax != nil {
+		query = query.Where(caseOutstandingExpr+" <= ?", *filter.OutstandingMax)
+	}
+	return query
+}
 
-	return cases, nil
+// pageCases orders a listing by its sort key and selects the requested page
+func (r *CaseRepository) pageCases(filter models.CaseFilter) *gorm.DB {
+	sortColumn, ok := caseSortColumns[filter.SortBy]
+	if !ok {
+		sortColumn = caseSortColumns[constants.CASE_SORT_CREATED_AT]
+	}
+	direction := "ASC"
+	if filter.SortDescending {
+		direction = "DESC"
+	}
+
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = constants.DEFAULT_CASE_PAGE_SIZE
+	}
+	page := max(filter.Page, 1)
+
+	return r.filterCases(filter).
+		Order(sortColumn + " " + direction).
+		Order("cases.id").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize)
+}
+
+// ListCases returns one page of the cases matching filter, and the number of
+// matching cases over all pages
+func (r *CaseRepository) ListCases(filter models.CaseFilter) ([]models.Case, int64, error) {
+	var total int64
+	if err := r.filterCases(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	cases := []models.Case{}
+	if err := r.pageCases(filter).Select("cases.*").Find(&cases).Error; err != nil {
+		return nil, 0, err
+	}
+	return cases, total, nil
 }
 
-func (r *CaseRepository) AssignCasesToAgency(agencyID string, caseIDs []string) error {
-	// Begin transaction
-	tx := r.db.Begin()
+// ListCasesWithAssignee works like ListCases and also returns the agent each
+// case is assigned to, joined in the same query
+func (r *CaseRepository) ListCasesWithAssignee(filter models.CaseFilter) ([]models.CaseWithAssignee, int64, error) {
+	var total int64
+	if err := r.filterCases(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
 
-	// Create agency case mappings
+	cases := []models.CaseWithAssignee{}
+	err := r.pageCases(filter).
+		Select("cases.*, assignee.id AS assignee_id, assignee.username AS assignee_username, assignee.email AS assignee_email").
+		Joins(`LEFT JOIN LATERAL (
+			SELECT users.id, users.username, users.email
+			FROM case_user_map
+			JOIN users ON users.id = case_user_map.user_id
+			WHERE case_user_map.case_id = cases.id AND case_user_map.unassigned_at IS NULL
+		) AS assignee ON true`).
+		Scan(&cases).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return cases, total, nil
+}
+
+// AssignCasesToAgency maps the cases to an agency. Status changes are left to
+// the caller.
+func (r *CaseRepository) AssignCasesToAgency(agencyID string, caseIDs []string, actorID *string) error {
+	mappings := make([]models.AgencyCaseMap, 0, len(caseIDs))
 	for _, caseID := range caseIDs {
-		mapping := models.AgencyCaseMap{
+		mappings = append(mappings, models.AgencyCaseMap{
 			AgencyID:   agencyID,
 			CaseID:     caseID,
 			AssignedAt: time.Now(),
+			AssignedBy: actorID,
 			UpdatedAt:  time.Now(),
-		}
-
-		if err := tx.Create(&mapping).Error; err != nil {
-			tx.Rollback()
-			return err
-		}
+		})
 	}
-
-	// Update case status
-	if err := tx.Model(&models.Case{}).
-		Where("id IN ?", caseIDs).
-		Update("case_status", "ASSIGNED").Error; err != nil {
-		tx.Rollback()
-		return err
+	if len(mappings) == 0 {
+		return nil
 	}
-
-	return tx.Commit().Error
+	return r.db.Create(&mappings).Error
 }
 
 func (r *CaseRepository) GetAssignedUserByCaseID(caseID string) (*models.User, error) {
@@ -76,8 +177,8 @@ func (r *CaseRepository) GetAssignedUserByCaseID(caseID string) (*models.User, e
 	err := r.db.Table("users").
 		Select("users.*").
 		Joins("JOIN case_user_map ON users.id = case_user_map.user_id").
-		Where("case_user_map.case_id = ?", caseID).
-		First(&user).Error
+		Where("case_user_map.case_id = ? AND case_user_map.unassigned_at IS NULL", caseID).
+		Take(&user).Error
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -89,28 +190,143 @@ func (r *CaseRepository) GetAssignedUserByCaseID(caseID string) (*models.User, e
 	return &user, nil
 }
 
-func (r *CaseRepository) GetAssignedCases(userID string) ([]models.Case, error) {
+func (r *CaseRepository) GetCase(caseID string) (*models.Case, error) {
+	var caseData models.Case
+	err := r.db.Where("id = ?", caseID).First(&caseData).Error
+	if err != nil {
+		return nil, err
+	}
+	return &caseData, nil
+}
+
+// GetCaseForUpdate loads a case and locks its row until the transaction ends
+func (r *CaseRepository) GetCaseForUpdate(caseID string) (*models.Case, error) {
+	var caseData models.Case
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", caseID).First(&caseData).Error
+	if err != nil {
+		return nil, err
+	}
+	return &caseData, nil
+}
+
+func (r *CaseRepository) UpdateCase(caseData *models.Case) error {
+	caseData.UpdatedAt = time.Now()
+	return r.db.Save(caseData).Error
+}
+
+// GetOpenCasesByLoanIDs returns the cases still under collection for the
+// given loans, keyed by loan ID. Loan IDs are only unique within a lender,
+// so lenderID narrows the cases to that lender's when set.
+func (r *CaseRepository) GetOpenCasesByLoanIDs(lenderID *string, loanIDs []string) (map[string]models.Case, error) {
+	query := r.db.Where("loan_id IN ? AND case_status NOT IN ?", loanIDs, constants.ClosedCaseStatuses)
+	if lenderID != nil {
+		query = query.Where("lender_id = ?", *lenderID)
+	}
 
 	var cases []models.Case
+	if err := query.Find(&cases).Error; err != nil {
+		return nil, err
+	}
 
-	err := r.db.Table("cases").
-		Select("cases.*").
-		Joins("JOIN case_user_map ON cases.id = case_user_map.case_id").
-		Where("case_user_map.user_id = ?", userID).
-		Find(&cases).Error
+	casesByLoanID := make(map[string]models.Case, len(cases))
+	for _, caseData := range cases {
+		casesByLoanID[caseData.LoanID] = caseData
+	}
+	return casesByLoanID, nil
+}
+
+// RefreshCases overwrites the fields a lender refreshes on every allocation
+// file, leaving status, assignments and trails untouched
+func (r *CaseRepository) RefreshCases(cases []models.Case) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, caseData := range cases {
+			err := tx.Model(&models.Case{}).Where("id = ?", caseData.ID).Updates(map[string]interface{}{
+				"emi_amount":               caseData.EMIAmount,
+				"principal_outstanding":    caseData.PrincipalOutstanding,
+				"interest_outstanding":     caseData.InterestOutstanding,
+				"emi_date":                 caseData.EMIDate,
+				"dpd_bucket":               caseData.DPDBucket,
+				"dpd":                      caseData.DPD,
+				"insurance_active":         caseData.InsuranceActive,
+				"emis_paid_till_date":      caseData.EMIsPaidTillDate,
+				"emis_pending":             caseData.EMIsPending,
+				"bounce_charges":           caseData.BounceCharges,
+				"nach_presentation_status": caseData.NachPresentationStatus,
+				"updated_at":               time.Now(),
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
 
+// IsCaseAccessible reports whether a case is assigned to the user, or, when
+// the matching scope is enabled, to the user's agency or lender
+func (r *CaseRepository) IsCaseAccessible(caseID, userID string, agencyScope, lenderScope bool) (bool, error) {
+	access := r.db.Where("EXISTS (?)",
+		r.db.Table("case_user_map").
+			Select("1").
+			Where("case_user_map.case_id = cases.id AND case_user_map.unassigned_at IS NULL AND case_user_map.user_id = ?", userID))
+	if agencyScope {
+		access = access.Or("EXISTS (?)",
+			r.db.Table("agency_case_map").
+				Select("1").
+				Joins("JOIN agency_user_map ON agency_user_map.agency_id = agency_case_map.agency_id AND agency_user_map.is_active = true").
+				Where("agency_case_map.case_id = cases.id AND agency_case_map.unassigned_at IS NULL AND agency_user_map.user_id = ?", userID))
+	}
+	if lenderScope {
+		access = access.Or("EXISTS (?)",
+			r.db.Table("lender_user_map").
+				Select("1").
+				Where("lender_user_map.lender_id = cases.lender_id AND lender_user_map.is_active = true AND lender_user_map.user_id = ?", userID))
+	}
+
+	var count int64
+	err := r.db.Model(&models.Case{}).
+		Where("cases.id = ?", caseID).
+		Where(access).
+		Count(&count).Error
 	if err != nil {
-		return nil, err
+		return false, err
 	}
+	return count > 0, nil
+}
 
-	return cases, nil
+// FindOpenCasesInBatches calls fn with every case still under collection,
+// batchSize cases at a time in ID order
+func (r *CaseRepository) FindOpenCasesInBatches(batchSize int, fn func([]models.Case) error) error {
+	cases := []models.Case{}
+	return r.db.Where("case_status NOT IN ?", constants.ClosedCaseStatuses).
+		FindInBatches(&cases, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(cases)
+		}).Error
 }
 
-func (r *CaseRepository) GetCase(caseID string) (*models.Case, error) {
-	var caseData models.Case
-	err := r.db.Where("id = ?", caseID).First(&caseData).Error
+func (r *CaseRepository) UpdateCaseDPD(caseID string, dpd int, dpdBucket string) error {
+	return r.db.Model(&models.Case{}).Where("id = ?", caseID).Updates(map[string]interface{}{
+		"dpd":        dpd,
+		"dpd_bucket": dpdBucket,
+		"updated_at": time.Now(),
+	}).Error
+}
+
+// GetAgencyIDsByCases returns the agency each case is allocated to, keyed by
+// case ID. Cases without an agency are left out.
+func (r *CaseRepository) GetAgencyIDsByCases(caseIDs []string) (map[string]string, error) {
+	var mappings []models.AgencyCaseMap
+	err := r.db.Table("agency_case_map").
+		Select("case_id, agency_id").
+		Where("case_id IN ? AND unassigned_at IS NULL", caseIDs).
+		Scan(&mappings).Error
 	if err != nil {
 		return nil, err
 	}
-	return &caseData, nil
+
+	agencyIDs := make(map[string]string, len(mappings))
+	for _, mapping := range mappings {
+		agencyIDs[mapping.CaseID] = mapping.AgencyID
+	}
+	return agencyIDs, nil
 }
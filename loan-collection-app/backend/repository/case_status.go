@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"backend/models"
+
+	"gorm.io/gorm"
+)
+
+type CaseStatusRepository struct {
+	db *gorm.DB
+}
+
+func NewCaseStatusRepository(db *gorm.DB) *CaseStatusRepository {
+	return &CaseStatusRepository{db: db}
+}
+
+func (r *CaseStatusRepository) CreateStatusChange(change *models.CaseStatusChange) error {
+	return r.db.Create(change).Error
+}
+
+// ListStatusChangesByCase returns the status history of a case, latest first
+func (r *CaseStatusRepository) ListStatusChangesByCase(caseID string) ([]models.CaseStatusChangeDetails, error) {
+	changes := []models.CaseStatusChangeDetails{}
+
+	result := r.db.Table("case_status_history").
+		Select("case_status_history.id, case_status_history.case_id, case_status_history.from_status, case_status_history.to_status, case_status_history.reason_code, case_status_history.remarks, case_status_history.actor_id, users.username AS actor_username, case_status_history.created_at").
+		Joins("LEFT JOIN users ON users.id = case_status_history.actor_id").
+		Where("case_status_history.case_id = ?", caseID).
+		Order("case_status_history.created_at DESC").
+		Scan(&changes)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"backend/models"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CustomerRepository struct {
+	db *gorm.DB
+}
+
+func NewCustomerRepository(db *gorm.DB) *CustomerRepository {
+	return &CustomerRepository{db: db}
+}
+
+func (r *CustomerRepository) GetByExternalID(externalCustomerID string) (*models.Customer, error) {
+	var customer models.Customer
+	err := r.db.Where("external_customer_id = ?", externalCustomerID).First(&customer).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &customer, nil
+}
+
+func (r *CustomerRepository) ListByExternalIDs(externalCustomerIDs []string) ([]models.Customer, error) {
+	customers := []models.Customer{}
+	err := r.db.Where("external_customer_id IN ?", externalCustomerIDs).Find(&customers).Error
+	return customers, err
+}
+
+// UpsertCustomers inserts customers or overwrites the details of existing
+// ones with the same external customer ID
+func (r *CustomerRepository) UpsertCustomers(customers []models.Customer) error {
+	if len(customers) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "external_customer_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "email", "phones", "addresses", "pincode", "updated_at"}),
+	}).Create(&customers).Error
+}
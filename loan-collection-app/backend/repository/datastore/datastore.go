@@ -13,6 +13,9 @@ func Get() {
 			os.Exit(1)
 		}
 	}
+	if RedisClient == nil {
+		InitRedisClient()
+	}
 	if RateLimiter == nil {
 		InitializeRateLimiter()
 	}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DPDRepository struct {
+	db *gorm.DB
+}
+
+func NewDPDRepository(db *gorm.DB) *DPDRepository {
+	return &DPDRepository{db: db}
+}
+
+// UpsertSnapshots stores the snapshots, replacing any taken earlier the same
+// day for the same case
+func (r *DPDRepository) UpsertSnapshots(snapshots []models.DPDSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "snapshot_date"}, {Name: "case_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"lender_id",
+			"agency_id",
+			"case_status",
+			"dpd",
+			"dpd_bucket",
+			"principal_outstanding",
+			"interest_outstanding",
+		}),
+	}).Create(&snapshots).Error
+}
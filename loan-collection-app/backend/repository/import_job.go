@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"backend/constants"
+	"backend/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ImportJobRepository struct {
+	db *gorm.DB
+}
+
+func NewImportJobRepository(db *gorm.DB) *ImportJobRepository {
+	return &ImportJobRepository{db: db}
+}
+
+func (r *ImportJobRepository) CreateImportJob(job *models.ImportJob) error {
+	return r.db.Create(job).Error
+}
+
+func (r *ImportJobRepository) UpdateImportJob(job *models.ImportJob) error {
+	job.UpdatedAt = time.Now()
+	return r.db.Save(job).Error
+}
+
+// FailImportJobs marks the jobs in one of the given statuses that have not
+// been updated since before as failed, returning how many were changed
+func (r *ImportJobRepository) FailImportJobs(statuses []string, before time.Time, reason string) (int64, error) {
+	now := time.Now()
+	result := r.db.Model(&models.ImportJob{}).
+		Where("status IN ? AND updated_at < ?", statuses, before).
+		Updates(map[string]interface{}{
+			"status":      constants.IMPORT_JOB_FAILED,
+			"error":       reason,
+			"finished_at": now,
+			"updated_at":  now,
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *ImportJobRepository) GetImportJob(jobID string) (*models.ImportJob, error) {
+	var job models.ImportJob
+	err := r.db.Where("id = ?", jobID).First(&job).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// JobLockRepository keeps scheduled jobs from running on several instances at once
+type JobLockRepository struct {
+	client *redis.Client
+}
+
+func NewJobLockRepository(client *redis.Client) *JobLockRepository {
+	return &JobLockRepository{client: client}
+}
+
+// AcquireJobLock takes the named lock for ttl and reports whether it was free
+func (r *JobLockRepository) AcquireJobLock(name string, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(context.Background(), fmt.Sprintf("job_lock:%s", name), 1, ttl).Result()
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"backend/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LenderRepository struct {
+	db *gorm.DB
+}
+
+func NewLenderRepository(db *gorm.DB) *LenderRepository {
+	return &LenderRepository{db: db}
+}
+
+func (r *LenderRepository) ListAllLenders() ([]models.Lender, error) {
+	var lenders []models.Lender
+	result := r.db.Where("status = ?", "ACTIVE").Order("lender_name").Find(&lenders)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return lenders, nil
+}
+
+func (r *LenderRepository) CreateLender(lender *models.Lender) error {
+	if lender.Status == "" {
+		lender.Status = "ACTIVE"
+	}
+	return r.db.Create(lender).Error
+}
+
+func (r *LenderRepository) AssignUserToLender(mapping *models.LenderUserMap) error {
+	// Check if user and lender exist
+	var user models.User
+	var lender models.Lender
+
+	if err := r.db.First(&user, "id = ?", mapping.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("user not found")
+		}
+		return err
+	}
+
+	if err := r.db.First(&lender, "id = ?", mapping.LenderID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("lender not found")
+		}
+		return err
+	}
+
+	mapping.AssignedAt = time.Now()
+	mapping.UpdatedAt = time.Now()
+	return r.db.Create(mapping).Error
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LoginAttemptRepository counts failed logins and holds lockouts in Redis.
+// Subjects are "user:<username>" or "ip:<address>".
+type LoginAttemptRepository struct {
+	client *redis.Client
+}
+
+func NewLoginAttemptRepository(client *redis.Client) *LoginAttemptRepository {
+	return &LoginAttemptRepository{client: client}
+}
+
+func loginFailuresKey(subject string) string {
+	return fmt.Sprintf("login_failures:%s", subject)
+}
+
+func loginLockKey(subject string) string {
+	return fmt.Sprintf("login_lock:%s", subject)
+}
+
+// IncrementFailures adds a failed attempt and returns the count within window
+func (r *LoginAttemptRepository) IncrementFailures(subject string, window time.Duration) (int64, error) {
+	ctx := context.Background()
+	var incr *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, loginFailuresKey(subject))
+		pipe.Expire(ctx, loginFailuresKey(subject), window)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+func (r *LoginAttemptRepository) ResetFailures(subject string) error {
+	return r.client.Del(context.Background(), loginFailuresKey(subject)).Err()
+}
+
+func (r *LoginAttemptRepository) Lock(subject string, duration time.Duration) error {
+	return r.client.Set(context.Background(), loginLockKey(subject), 1, duration).Err()
+}
+
+// GetLockRemaining returns how long the subject stays locked, or 0
+func (r *LoginAttemptRepository) GetLockRemaining(subject string) (time.Duration, error) {
+	ttl, err := r.client.PTTL(context.Background(), loginLockKey(subject)).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// Unlock removes the lockout and the failure count of the subject
+func (r *LoginAttemptRepository) Unlock(subject string) error {
+	return r.client.Del(context.Background(), loginLockKey(subject), loginFailuresKey(subject)).Err()
+}
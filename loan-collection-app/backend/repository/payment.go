@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"backend/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PaymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+func (r *PaymentRepository) CreatePaymentLink(link *models.PaymentLink) error {
+	return r.db.Create(link).Error
+}
+
+// ListPaymentLinksByCase returns the payment links of a case, latest first
+func (r *PaymentRepository) ListPaymentLinksByCase(caseID string) ([]models.PaymentLink, error) {
+	links := []models.PaymentLink{}
+	err := r.db.Where("case_id = ?", caseID).Order("created_at DESC").Find(&links).Error
+	return links, err
+}
+
+func (r *PaymentRepository) GetPaymentLink(linkID string) (*models.PaymentLink, error) {
+	var link models.PaymentLink
+	err := r.db.Where("id = ?", linkID).First(&link).Error
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// UpdateCreatedPaymentLink stores what the provider returned for a link
+// recorded before it was created
+func (r *PaymentRepository) UpdateCreatedPaymentLink(link *models.PaymentLink) error {
+	return r.db.Model(&models.PaymentLink{}).Where("id = ?", link.ID).Updates(map[string]interface{}{
+		"provider_reference": link.ProviderReference,
+		"url":                link.URL,
+		"status":             link.Status,
+		"updated_at":         time.Now(),
+	}).Error
+}
+
+func (r *PaymentRepository) UpdatePaymentLinkStatus(linkID, status string) error {
+	return r.db.Model(&models.PaymentLink{}).Where("id = ?", linkID).Updates(map[string]interface{}{
+		"status":     status,
+		"updated_at": time.Now(),
+	}).Error
+}
+
+// PaymentEventExists reports whether a provider event has already been posted
+func (r *PaymentRepository) PaymentEventExists(eventID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Payment{}).Where("event_id = ?", eventID).Count(&count).Error
+	return count > 0, err
+}
+
+// GetPaymentByReference returns a payment on the case carrying the
+// reference, or nil when there is none
+func (r *PaymentRepository) GetPaymentByReference(caseID, reference string) (*models.Payment, error) {
+	var payment models.Payment
+	err := r.db.Where("case_id = ? AND payment_reference = ?", caseID, reference).Take(&payment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *PaymentRepository) CreatePayment(payment *models.Payment) error {
+	return r.db.Create(payment).Error
+}
+
+func (r *PaymentRepository) GetPaymentLinkByProviderReference(provider, reference string) (*models.PaymentLink, error) {
+	var link models.PaymentLink
+	err := r.db.Where("provider = ? AND provider_reference = ?", provider, reference).First(&link).Error
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// SumPaymentsSinceEMIDate totals, per case, the payments received from the
+// case's EMI date up to and including the given day
+func (r *PaymentRepository) SumPaymentsSinceEMIDate(caseIDs []string, until time.Time) (map[string]float64, error) {
+	var rows []struct {
+		CaseID string
+		Total  float64
+	}
+	err := r.db.Table("payments").
+		Select("payments.case_id, SUM(payments.amount) AS total").
+		Joins("JOIN cases ON cases.id = payments.case_id").
+		Where("payments.case_id IN ? AND payments.paid_at >= cases.emi_date AND payments.paid_at < ?", caseIDs, until.AddDate(0, 0, 1)).
+		Group("payments.case_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		totals[row.CaseID] = row.Total
+	}
+	return totals, nil
+}
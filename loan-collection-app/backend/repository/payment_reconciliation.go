@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"backend/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type PaymentReconciliationRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentReconciliationRepository(db *gorm.DB) *PaymentReconciliationRepository {
+	return &PaymentReconciliationRepository{db: db}
+}
+
+func (r *PaymentReconciliationRepository) CreateReconciliation(reconciliation *models.PaymentReconciliation) error {
+	return r.db.Create(reconciliation).Error
+}
+
+func (r *PaymentReconciliationRepository) UpdateSummary(reconciliation *models.PaymentReconciliation) error {
+	return r.db.Model(reconciliation).Update("summary", reconciliation.Summary).Error
+}
+
+func (r *PaymentReconciliationRepository) CreateLines(lines []models.PaymentReconciliationLine) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(&lines, 500).Error
+}
+
+// GetReconciliation returns a reconciliation, or nil when it does not exist
+// or belongs to another lender than lenderID
+func (r *PaymentReconciliationRepository) GetReconciliation(id string, lenderID *string) (*models.PaymentReconciliation, error) {
+	query := r.db.Where("id = ?", id)
+	if lenderID != nil {
+		query = query.Where("lender_id = ?", *lenderID)
+	}
+	var reconciliation models.PaymentReconciliation
+	if err := query.Take(&reconciliation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &reconciliation, nil
+}
+
+// ListReconciliations returns one page of reconciliations, latest first,
+// and the number over all pages. lenderID limits them to one lender.
+func (r *PaymentReconciliationRepository) ListReconciliations(lenderID *string, page, pageSize int) ([]models.PaymentReconciliation, int64, error) {
+	query := r.db.Model(&models.PaymentReconciliation{})
+	if lenderID != nil {
+		query = query.Where("lender_id = ?", *lenderID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	reconciliations := []models.PaymentReconciliation{}
+	err := query.Order("created_at DESC").Order("id").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&reconciliations).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return reconciliations, total, nil
+}
+
+// ListLines returns one page of the lines of a reconciliation in file
+// order, and the number of matching lines over all pages
+func (r *PaymentReconciliationRepository) ListLines(reconciliationID string, filter models.ReconciliationLineFilter) ([]models.PaymentReconciliationLine, int64, error) {
+	query := r.db.Model(&models.PaymentReconciliationLine{}).Where("reconciliation_id = ?", reconciliationID)
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	lines := []models.PaymentReconciliationLine{}
+	err := query.Order("row_number").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&lines).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return lines, total, nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PermissionRepository struct {
+	db *gorm.DB
+}
+
+func NewPermissionRepository(db *gorm.DB) *PermissionRepository {
+	return &PermissionRepository{db: db}
+}
+
+// ListPermissions retrieves all permissions
+func (r *PermissionRepository) ListPermissions() ([]models.Permission, error) {
+	var permissions []models.Permission
+	err := r.db.Order("name").Find(&permissions).Error
+	return permissions, err
+}
+
+// CreateMissingPermissions inserts the permissions that do not exist yet
+func (r *PermissionRepository) CreateMissingPermissions(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	permissions := make([]models.Permission, 0, len(names))
+	for _, name := range names {
+		permissions = append(permissions, models.Permission{Name: name})
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoNothing: true,
+	}).Create(&permissions).Error
+}
+
+// GetPermissionsByNames retrieves the permissions with the given names
+func (r *PermissionRepository) GetPermissionsByNames(names []string) ([]models.Permission, error) {
+	var permissions []models.Permission
+	err := r.db.Where("name IN ?", names).Find(&permissions).Error
+	return permissions, err
+}
+
+// GetPermissionsByRole retrieves the permissions granted to a role
+func (r *PermissionRepository) GetPermissionsByRole(roleID string) ([]models.Permission, error) {
+	var permissions []models.Permission
+	err := r.db.Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Where("role_permissions.role_id = ?", roleID).
+		Order("permissions.name").
+		Find(&permissions).Error
+	return permissions, err
+}
+
+// AttachPermissionsToRole grants permissions to a role, ignoring existing grants
+func (r *PermissionRepository) AttachPermissionsToRole(roleID string, permissionIDs []string) error {
+	if len(permissionIDs) == 0 {
+		return nil
+	}
+	rolePermissions := make([]models.RolePermission, 0, len(permissionIDs))
+	for _, permissionID := range permissionIDs {
+		rolePermissions = append(rolePermissions, models.RolePermission{
+			RoleID:       roleID,
+			PermissionID: permissionID,
+		})
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&rolePermissions).Error
+}
+
+// DetachPermissionFromRole revokes a permission from a role
+func (r *PermissionRepository) DetachPermissionFromRole(roleID, permissionID string) error {
+	return r.db.Where("role_id = ? AND permission_id = ?", roleID, permissionID).
+		Delete(&models.RolePermission{}).Error
+}
+
+// GetPermissionNamesByUser resolves the permissions granted through all
+// active roles of a user
+func (r *PermissionRepository) GetPermissionNamesByUser(userID string) ([]string, error) {
+	var names []string
+	err := r.db.Table("permissions").
+		Distinct("permissions.name").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN user_role_map ON user_role_map.role_id = role_permissions.role_id").
+		Where("user_role_map.user_id = ? AND user_role_map.is_active = true", userID).
+		Pluck("permissions.name", &names).Error
+	return names, err
+}
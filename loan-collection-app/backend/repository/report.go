@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"backend/constants"
+	"backend/models"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// reportStints returns the query listing every agency or agent holding of a
+// case that overlaps the report period, clipped to the period, together with
+// the group it counts towards
+func reportStints(groupBy string) (string, error) {
+	const agencyStints = `SELECT m.case_id, NULL::uuid AS user_id, %s AS group_id, %s AS group_name,
+			GREATEST(m.assigned_at, @from) AS starts_at,
+			LEAST(COALESCE(m.unassigned_at, @to), @to) AS ends_at
+		FROM agency_case_map m
+		JOIN cases ON cases.id = m.case_id
+		JOIN agencies ON agencies.id = m.agency_id
+		WHERE m.assigned_at < @to AND (m.unassigned_at IS NULL OR m.unassigned_at > @from)
+			AND (CAST(@lender_id AS uuid) IS NULL OR cases.lender_id = CAST(@lender_id AS uuid))
+			AND (CAST(@agency_id AS uuid) IS NULL OR m.agency_id = CAST(@agency_id AS uuid))`
+
+	switch groupBy {
+	case constants.REPORT_GROUP_AGENCY:
+		return fmt.Sprintf(agencyStints, "m.agency_id::text", "agencies.agency_name"), nil
+	case constants.REPORT_GROUP_DPD_BUCKET:
+		return fmt.Sprintf(agencyStints, "COALESCE(cases.dpd_bucket, '')", "COALESCE(cases.dpd_bucket, '')"), nil
+	case constants.REPORT_GROUP_LOAN_TYPE:
+		return fmt.Sprintf(agencyStints, "COALESCE(cases.loan_description, '')", "COALESCE(cases.loan_description, '')"), nil
+	case constants.REPORT_GROUP_AGENT:
+		return `SELECT m.case_id, m.user_id, m.user_id::text AS group_id, users.username AS group_name,
+				GREATEST(m.assigned_at, @from) AS starts_at,
+				LEAST(COALESCE(m.unassigned_at, @to), @to) AS ends_at
+			FROM case_user_map m
+			JOIN cases ON cases.id = m.case_id
+			JOIN users ON users.id = m.user_id
+			WHERE m.assigned_at < @to AND (m.unassigned_at IS NULL OR m.unassigned_at > @from)
+				AND (CAST(@lender_id AS uuid) IS NULL OR cases.lender_id = CAST(@lender_id AS uuid))
+				AND (CAST(@agency_id AS uuid) IS NULL OR EXISTS (
+					SELECT 1 FROM agency_user_map
+					WHERE agency_user_map.user_id = m.user_id AND agency_user_map.agency_id = CAST(@agency_id AS uuid)
+				))`, nil
+	}
+	return "", fmt.Errorf("unknown report grouping %q", groupBy)
+}
+
+// GetCollectionPerformance aggregates, per group, the holdings that overlap
+// the period. A holding is credited with the payments, trails and
+// resolutions that happened while it lasted; agent holdings only count the
+// agent's own trails. The allocated amount of a case is counted once per
+// group, as what was outstanding when the group first held it within the
+// period, i.e. today's outstanding plus everything paid since, so a case
+// that leaves and comes back is not allocated twice. A promise to pay is
+// due once its date plus the grace days has passed, and kept when a payment
+// arrived between the promise and that deadline.
+func (r *ReportRepository) GetCollectionPerformance(filter models.CollectionPerformanceFilter, ptpGraceDays int, now time.Time) ([]models.CollectionPerformanceRow, error) {
+	stints, err := reportStints(filter.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `WITH stints AS (` + stints + `),
+		allocations AS (
+			SELECT group_cases.group_id,
+				SUM(COALESCE(cases.principal_outstanding, 0) + COALESCE(cases.interest_outstanding, 0) + (
+					SELECT COALESCE(SUM(p.amount), 0) FROM payments p
+					WHERE p.case_id = group_cases.case_id AND p.paid_at >= group_cases.starts_at
+				)) AS allocated_amount
+			FROM (
+				SELECT group_id, case_id, MIN(starts_at) AS starts_at
+				FROM stints
+				GROUP BY group_id, case_id
+			) AS group_cases
+			JOIN cases ON cases.id = group_cases.case_id
+			GROUP BY group_cases.group_id
+		),
+		holdings AS (
+			SELECT stints.group_id, stints.group_name, stints.case_id,
+				(
+					SELECT COALESCE(SUM(p.amount), 0) FROM payments p
+					WHERE p.case_id = stints.case_id AND p.paid_at >= stints.starts_at AND p.paid_at < stints.ends_at
+				) AS collected_amount,
+				EXISTS (
+					SELECT 1 FROM case_status_history h
+					WHERE h.case_id = stints.case_id AND h.to_status IN @resolved
+						AND h.created_at >= stints.starts_at AND h.created_at < stints.ends_at
+				) AS resolved,
+				trail_counts.trails,
+				trail_counts.contacts,
+				trail_counts.ptps_due,
+				trail_counts.ptps_kept
+			FROM stints
+			CROSS JOIN LATERAL (
+				SELECT COUNT(*) AS trails,
+					COUNT(*) FILTER (WHERE t.disposition IN @contacted) AS contacts,
+					COUNT(*) FILTER (WHERE t.disposition = @ptp AND t.payment_date IS NOT NULL
+						AND (t.payment_date + CAST(@grace_days AS int) < CAST(@today AS date) OR kept.paid)) AS ptps_due,
+					COUNT(*) FILTER (WHERE t.disposition = @ptp AND kept.paid) AS ptps_kept
+				FROM trails t
+				CROSS JOIN LATERAL (
+					SELECT EXISTS (
+						SELECT 1 FROM payments p
+						WHERE p.case_id = t.case_id AND p.paid_at >= t.created_at
+							AND p.paid_at < t.payment_date + CAST(@grace_days AS int) + 1
+					) AS paid
+				) AS kept
+				WHERE t.case_id = stints.case_id
+					AND t.created_at >= stints.starts_at AND t.created_at < stints.ends_at
+					AND (stints.user_id IS NULL OR t.user_id = stints.user_id)
+			) AS trail_counts
+		)
+		SELECT holdings.group_id, holdings.group_name,
+			COUNT(DISTINCT holdings.case_id) AS cases,
+			MAX(allocations.allocated_amount) AS allocated_amount,
+			SUM(holdings.collected_amount) AS collected_amount,
+			COUNT(DISTINCT holdings.case_id) FILTER (WHERE holdings.resolved) AS resolved_cases,
+			COUNT(DISTINCT holdings.case_id) FILTER (WHERE holdings.contacts > 0) AS contacted_cases,
+			SUM(holdings.trails) AS trails,
+			SUM(holdings.ptps_due) AS ptps_due,
+			SUM(holdings.ptps_kept) AS ptps_kept
+		FROM holdings
+		JOIN allocations ON allocations.group_id = holdings.group_id
+		GROUP BY holdings.group_id, holdings.group_name
+		ORDER BY holdings.group_name, holdings.group_id`
+
+	rows := []models.CollectionPerformanceRow{}
+	err = r.db.Raw(query, map[string]interface{}{
+		"from":       filter.From,
+		"to":         filter.To,
+		"lender_id":  filter.LenderID,
+		"agency_id":  filter.AgencyID,
+		"resolved":   constants.ResolvedCaseStatuses,
+		"contacted":  constants.ContactedDispositions,
+		"ptp":        constants.DISPOSITION_PROMISE_TO_PAY,
+		"grace_days": ptpGraceDays,
+		"today":      now.Format(time.DateOnly),
+	}).Scan(&rows).Error
+	return rows, err
+}
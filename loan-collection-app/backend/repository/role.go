@@ -32,6 +32,14 @@ func (r *RoleRepository) UpdateRole(roleID uint64, roleName, description string)
 	}).Error
 }
 
+func (r *RoleRepository) GetRoleByID(roleID uint64) (*models.Role, error) {
+	var role models.Role
+	if err := r.db.First(&role, roleID).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
 // DeleteRole soft deletes a role
 func (r *RoleRepository) DeleteRole(roleID uint64) error {
 	return r.db.Delete(&models.Role{}, roleID).Error
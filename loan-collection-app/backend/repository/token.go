@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TokenRepository keeps refresh tokens and access token revocations in Redis
+type TokenRepository struct {
+	client *redis.Client
+}
+
+func NewTokenRepository(client *redis.Client) *TokenRepository {
+	return &TokenRepository{client: client}
+}
+
+func refreshTokenKey(tokenHash string) string {
+	return fmt.Sprintf("refresh_token:%s", tokenHash)
+}
+
+func usedRefreshTokenKey(tokenHash string) string {
+	return fmt.Sprintf("refresh_token_used:%s", tokenHash)
+}
+
+func userRefreshTokensKey(userID string) string {
+	return fmt.Sprintf("user_refresh_tokens:%s", userID)
+}
+
+func revokedAccessTokenKey(jti string) string {
+	return fmt.Sprintf("revoked_jti:%s", jti)
+}
+
+func userTokensRevokedBeforeKey(userID string) string {
+	return fmt.Sprintf("tokens_revoked_before:%s", userID)
+}
+
+func (r *TokenRepository) SaveRefreshToken(tokenHash, userID string, ttl time.Duration) error {
+	ctx := context.Background()
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, refreshTokenKey(tokenHash), userID, ttl)
+		pipe.SAdd(ctx, userRefreshTokensKey(userID), tokenHash)
+		pipe.Expire(ctx, userRefreshTokensKey(userID), ttl)
+		return nil
+	})
+	return err
+}
+
+// ConsumeRefreshToken deletes a refresh token and returns its user, so each
+// token can be exchanged only once. Consumed tokens are remembered for ttl to
+// detect reuse. An empty user ID means the token is unknown.
+func (r *TokenRepository) ConsumeRefreshToken(tokenHash string, ttl time.Duration) (string, error) {
+	ctx := context.Background()
+	userID, err := r.client.GetDel(ctx, refreshTokenKey(tokenHash)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SRem(ctx, userRefreshTokensKey(userID), tokenHash)
+		pipe.Set(ctx, usedRefreshTokenKey(tokenHash), userID, ttl)
+		return nil
+	})
+	return userID, err
+}
+
+// GetRefreshTokenReuser returns the user of an already consumed refresh token
+func (r *TokenRepository) GetRefreshTokenReuser(tokenHash string) (string, error) {
+	userID, err := r.client.Get(context.Background(), usedRefreshTokenKey(tokenHash)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return userID, err
+}
+
+func (r *TokenRepository) DeleteRefreshToken(tokenHash, userID string) error {
+	ctx := context.Background()
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, refreshTokenKey(tokenHash))
+		pipe.SRem(ctx, userRefreshTokensKey(userID), tokenHash)
+		return nil
+	})
+	return err
+}
+
+func (r *TokenRepository) DeleteUserRefreshTokens(userID string) error {
+	ctx := context.Background()
+	tokenHashes, err := r.client.SMembers(ctx, userRefreshTokensKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := []string{userRefreshTokensKey(userID)}
+	for _, tokenHash := range tokenHashes {
+		keys = append(keys, refreshTokenKey(tokenHash))
+	}
+	return r.client.Del(ctx, keys...).Err()
+}
+
+// RevokeAccessToken denylists a token ID until the token would have expired
+func (r *TokenRepository) RevokeAccessToken(jti string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+	return r.client.Set(context.Background(), revokedAccessTokenKey(jti), 1, ttl).Err()
+}
+
+func (r *TokenRepository) IsAccessTokenRevoked(jti string) (bool, error) {
+	count, err := r.client.Exists(context.Background(), revokedAccessTokenKey(jti)).Result()
+	return count > 0, err
+}
+
+// RevokeUserTokensBefore invalidates every access token of a user issued
+// before the given time. ttl only needs to cover the access token lifetime.
+func (r *TokenRepository) RevokeUserTokensBefore(userID string, before time.Time, ttl time.Duration) error {
+	return r.client.Set(context.Background(), userTokensRevokedBeforeKey(userID), before.Unix(), ttl).Err()
+}
+
+// GetUserTokensRevokedBefore returns the unix time before which the user's
+// access tokens are invalid, or 0 when none were revoked
+func (r *TokenRepository) GetUserTokensRevokedBefore(userID string) (int64, error) {
+	value, err := r.client.Get(context.Background(), userTokensRevokedBeforeKey(userID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
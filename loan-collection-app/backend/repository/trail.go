@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"backend/models"
+
+	"gorm.io/gorm"
+)
+
+type TrailRepository struct {
+	db *gorm.DB
+}
+
+func NewTrailRepository(db *gorm.DB) *TrailRepository {
+	return &TrailRepository{db: db}
+}
+
+func (r *TrailRepository) CreateTrail(trail *models.Trail) error {
+	return r.db.Create(trail).Error
+}
+
+// ListTrailsByCase returns the trails of a case, latest first
+func (r *TrailRepository) ListTrailsByCase(caseID string) ([]models.TrailDetails, error) {
+	trails := []models.TrailDetails{}
+
+	result := r.db.Table("trails").
+		Select("trails.id, trails.case_id, trails.user_id, users.username, trails.disposition, trails.payment_date, trails.follow_up_date, trails.remarks, trails.created_at").
+		Joins("JOIN users ON users.id = trails.user_id").
+		Where("trails.case_id = ?", caseID).
+		Order("trails.created_at DESC").
+		Scan(&trails)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return trails, nil
+}
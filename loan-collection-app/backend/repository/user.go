@@ -77,18 +77,28 @@ func (r *UserRepository) FindByID(userID string) (*models.User, error) {
 	return &user, nil
 }
 
-func (r *UserRepository) ListAllUsers() ([]models.User, error) {
-	var users []models.User
-	result := r.db.Find(&users)
+// ListAllUsers returns every user with their active role names aggregated
+// in the same query
+func (r *UserRepository) ListAllUsers() ([]models.UserWithRoles, error) {
+	users := []models.UserWithRoles{}
+	result := r.db.Table("users").
+		Select("users.*, COALESCE(json_agg(roles.role_name ORDER BY roles.role_name) FILTER (WHERE roles.id IS NOT NULL), '[]') AS role_names").
+		Joins("LEFT JOIN user_role_map ON user_role_map.user_id = users.id AND user_role_map.is_active = true").
+		Joins("LEFT JOIN roles ON roles.id = user_role_map.role_id").
+		Group("users.id").
+		Order("users.username").
+		Scan(&users)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return users, nil
 }
 
+// GetUserAgencyID returns the agency the user is currently a member of, or
+// "" when they have none. Ended memberships are ignored.
 func (r *UserRepository) GetUserAgencyID(userID string) (string, error) {
 	var agencyUserMap models.AgencyUserMap
-	result := r.db.Where("user_id = ?", userID).First(&agencyUserMap)
+	result := r.db.Where("user_id = ? AND is_active = true", userID).Order("assigned_at DESC").First(&agencyUserMap)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return "", nil
@@ -97,3 +107,15 @@ func (r *UserRepository) GetUserAgencyID(userID string) (string, error) {
 	}
 	return agencyUserMap.AgencyID, nil
 }
+
+func (r *UserRepository) GetUserLenderID(userID string) (string, error) {
+	var lenderUserMap models.LenderUserMap
+	result := r.db.Where("user_id = ? AND is_active = true", userID).First(&lenderUserMap)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", result.Error
+	}
+	return lenderUserMap.LenderID, nil
+}
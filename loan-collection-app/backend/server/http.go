@@ -3,6 +3,9 @@ package server
 import (
 	"backend/handlers"
 	"backend/handlers/middlewares"
+	"backend/models"
+	"backend/repository/datastore"
+	"backend/services"
 	"fmt"
 
 	"github.com/gin-gonic/gin"
@@ -16,10 +19,75 @@ func Initialize() {
 	router := gin.Default()
 	gin.SetMode(gin.DebugMode)
 	router.Use(gin.CustomRecovery(middlewares.ErrorHandler))
+	setTrustedProxies(router)
 	route(&router.RouterGroup)
+	syncPermissions()
+	failStaleImportJobs()
+	startSchedulers()
 	router.Run(fmt.Sprintf(":%s", viper.GetString("PORT")))
 }
 
+// setTrustedProxies makes client IPs, which rate limits, login lockouts and
+// the audit log key on, come from forwarding headers only when set by the
+// TRUSTED_PROXIES. gin trusts every proxy by default, so without the key or
+// with an invalid list no proxy is trusted and the peer address is used.
+func setTrustedProxies(router *gin.Engine) {
+	if !viper.IsSet("TRUSTED_PROXIES") {
+		router.SetTrustedProxies(nil)
+		return
+	}
+	if err := router.SetTrustedProxies(viper.GetStringSlice("TRUSTED_PROXIES")); err != nil {
+		fmt.Println("Error setting trusted proxies:", err)
+		router.SetTrustedProxies(nil)
+	}
+}
+
+// syncPermissions records every permission used by the routes so that it can
+// be granted to roles
+func syncPermissions() {
+	env := &models.Env{
+		AuthDtos: &models.Auth{},
+		DbConn:   datastore.PostgeSQLConn,
+	}
+	if err := services.SyncPermissions(env, middlewares.RegisteredPermissions()); err != nil {
+		fmt.Println("Error syncing permissions:", err)
+	}
+}
+
+// failStaleImportJobs closes the background uploads that a previous run of
+// the server never finished
+func failStaleImportJobs() {
+	env := &models.Env{
+		AuthDtos: &models.Auth{},
+		DbConn:   datastore.PostgeSQLConn,
+	}
+	failed, err := services.FailStaleImportJobs(env)
+	if err != nil {
+		fmt.Println("Error failing stale import jobs:", err)
+		return
+	}
+	if failed > 0 {
+		fmt.Printf("Marked %d interrupted import jobs as failed\n", failed)
+	}
+}
+
+// startSchedulers starts the in-process jobs enabled in the config
+func startSchedulers() {
+	if !viper.GetBool("DPD_RECOMPUTE_SCHEDULER_ENABLED") {
+		return
+	}
+	zapLogger, _ := zap.NewProductionConfig().Build()
+	env := &models.Env{
+		Logger:      zapLogger,
+		AuthDtos:    &models.Auth{},
+		DbConn:      datastore.PostgeSQLConn,
+		RedisClient: datastore.RedisClient,
+	}
+	if err := services.StartDPDScheduler(env); err != nil {
+		fmt.Println("Error starting DPD scheduler:", err)
+	}
+}
+
 func route(router *gin.RouterGroup) {
 	zapLogger, _ := zap.NewProductionConfig().Build()
 	validator := validator.New()
@@ -28,9 +96,16 @@ func route(router *gin.RouterGroup) {
 	router.Use(middlewares.SetCorsHeaders())
 	agentRoutes := router.Group("/api")
 	agentRoutesV1 := agentRoutes.Group("/v1")
+	agentRoutesV1.Use(middlewares.AuditMiddleware)
 	{
 		// Authentication
-		agentRoutesV1.POST("/login", handlers.LoginHandler)
+		agentRoutesV1.POST("/login", middlewares.IPRateLimitMiddleware, handlers.LoginHandler)
+		agentRoutesV1.POST("/token/refresh", middlewares.IPRateLimitMiddleware, handlers.RefreshTokenHandler)
+		agentRoutesV1.POST("/logout", middlewares.AuthMiddleware, handlers.LogoutHandler)
+		agentRoutesV1.POST("/logout-all", middlewares.AuthMiddleware, handlers.LogoutAllHandler)
+
+		// Payment gateway callbacks, verified by signature
+		agentRoutesV1.POST("/payments/webhook", handlers.PaymentWebhookHandler)
 		agentRoutesV1.POST("/users/register",
 			middlewares.AuthMiddleware,
 			middlewares.PermissionMiddleware("create_user"),
@@ -41,6 +116,21 @@ func route(router *gin.RouterGroup) {
 			middlewares.PermissionMiddleware("view_users"),
 			handlers.ListAllUsers)
 
+		agentRoutesV1.POST("/users/:user_id/unlock",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("unlock_user"),
+			handlers.UnlockUserHandler)
+
+		agentRoutesV1.GET("/audit-events",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("view_audit_events"),
+			handlers.ListAuditEvents)
+
+		agentRoutesV1.GET("/reports/collection-performance",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("view_reports"),
+			handlers.GetCollectionPerformance)
+
 		// Protected routes
 		agentRoutesV1.GET("/cases",
 			middlewares.AuthMiddleware,
@@ -50,22 +140,65 @@ func route(router *gin.RouterGroup) {
 		agentRoutesV1.GET("/cases/:caseID",
 			middlewares.AuthMiddleware,
 			middlewares.PermissionMiddleware("view_my_cases"),
+			middlewares.CaseAccessMiddleware,
 			handlers.GetCaseDetails)
 
 		agentRoutesV1.POST("/cases/:caseID/trails",
 			middlewares.AuthMiddleware,
 			middlewares.PermissionMiddleware("add_trail"),
+			middlewares.CaseAccessMiddleware,
 			handlers.AddTrail)
 
 		agentRoutesV1.GET("/cases/:caseID/trails",
 			middlewares.AuthMiddleware,
 			middlewares.PermissionMiddleware("view_trails"),
+			middlewares.CaseAccessMiddleware,
 			handlers.GetTrails)
 
-		agentRoutesV1.GET("/cases/:caseID/payment-link",
+		agentRoutesV1.POST("/cases/:caseID/status",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("update_case_status"),
+			middlewares.CaseAccessMiddleware,
+			handlers.UpdateCaseStatus)
+
+		agentRoutesV1.GET("/cases/:caseID/status-history",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("view_my_cases"),
+			middlewares.CaseAccessMiddleware,
+			handlers.GetCaseStatusHistory)
+
+		agentRoutesV1.GET("/cases/:caseID/assignment-history",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("view_my_cases"),
+			middlewares.CaseAccessMiddleware,
+			handlers.GetCaseAssignmentHistory)
+
+		agentRoutesV1.POST("/cases/:caseID/payment-link",
 			middlewares.AuthMiddleware,
 			middlewares.PermissionMiddleware("generate_payment_link"),
-			handlers.GetPaymentLink)
+			middlewares.CaseAccessMiddleware,
+			handlers.CreatePaymentLink)
+
+		agentRoutesV1.GET("/cases/:caseID/payment-links",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("generate_payment_link"),
+			middlewares.CaseAccessMiddleware,
+			handlers.ListPaymentLinks)
+
+		agentRoutesV1.POST("/payments/reconciliations",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("reconcile_payments"),
+			handlers.ReconcilePaymentsHandler)
+
+		agentRoutesV1.GET("/payments/reconciliations",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("reconcile_payments"),
+			handlers.ListPaymentReconciliations)
+
+		agentRoutesV1.GET("/payments/reconciliations/:reconciliation_id",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("reconcile_payments"),
+			handlers.GetPaymentReconciliation)
 
 		// Role Management Routes (Admin Only)
 		agentRoutesV1.POST("/roles",
@@ -103,11 +236,46 @@ func route(router *gin.RouterGroup) {
 			middlewares.PermissionMiddleware("view_roles"),
 			handlers.ListAllRoles)
 
+		agentRoutesV1.GET("/permissions",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("view_permissions"),
+			handlers.ListPermissionCatalogue)
+
+		agentRoutesV1.GET("/roles/:id/permissions",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("view_roles"),
+			handlers.GetRolePermissions)
+
+		agentRoutesV1.POST("/roles/:id/permissions",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("manage_role_permissions"),
+			handlers.AttachPermissionsToRole)
+
+		agentRoutesV1.DELETE("/roles/:id/permissions/:permission",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("manage_role_permissions"),
+			handlers.DetachPermissionFromRole)
+
 		agentRoutesV1.GET("/permissions/me",
 			middlewares.AuthMiddleware,
 			middlewares.PermissionMiddleware("view_my_permissions"),
 			handlers.GetMyPermissions)
 
+		agentRoutesV1.GET("/lenders",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("view_lenders"),
+			handlers.ListAllLenders)
+
+		agentRoutesV1.POST("/lenders",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("create_lender"),
+			handlers.CreateLender)
+
+		agentRoutesV1.POST("/lenders/users",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("assign_lender_user"),
+			handlers.AssignUserToLender)
+
 		agentRoutesV1.GET("/agencies",
 			middlewares.AuthMiddleware,
 			middlewares.PermissionMiddleware("view_agencies"),
@@ -143,6 +311,11 @@ func route(router *gin.RouterGroup) {
 			middlewares.PermissionMiddleware("upload_cases"),
 			handlers.UploadCasesHandler)
 
+		agentRoutesV1.GET("/cases/upload/jobs/:job_id",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("upload_cases"),
+			handlers.GetImportJobHandler)
+
 		agentRoutesV1.GET("/cases/unassigned",
 			middlewares.AuthMiddleware,
 			middlewares.PermissionMiddleware("view_unassigned_cases"),
@@ -153,6 +326,36 @@ func route(router *gin.RouterGroup) {
 			middlewares.PermissionMiddleware("assign_cases"),
 			handlers.AssignCasesHandler)
 
+		agentRoutesV1.POST("/cases/recall",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("recall_cases"),
+			handlers.RecallCasesHandler)
+
+		agentRoutesV1.POST("/cases/reallocate",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("recall_cases"),
+			handlers.ReallocateCasesHandler)
+
+		agentRoutesV1.POST("/cases/allocate/preview",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("allocate_cases"),
+			handlers.PreviewAllocation)
+
+		agentRoutesV1.POST("/cases/allocate",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("allocate_cases"),
+			handlers.CommitAllocation)
+
+		agentRoutesV1.GET("/allocation/rules",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("view_allocation_rules"),
+			handlers.ListAllocationRules)
+
+		agentRoutesV1.PUT("/allocation/rules/:agency_id",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("manage_allocation_rules"),
+			handlers.SaveAllocationRule)
+
 		// Add these routes to your existing routes
 		agentRoutesV1.GET("/agencies/cases",
 			middlewares.AuthMiddleware,
@@ -169,6 +372,31 @@ func route(router *gin.RouterGroup) {
 			middlewares.PermissionMiddleware("assign_agency_cases"),
 			handlers.AssignAgencyCaseHandler)
 
+		agentRoutesV1.POST("/agencies/cases/recall",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("reassign_agency_cases"),
+			handlers.RecallAgentCasesHandler)
+
+		agentRoutesV1.POST("/agencies/cases/reassign",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("reassign_agency_cases"),
+			handlers.ReassignCasesHandler)
+
+		agentRoutesV1.POST("/agencies/cases/auto-assign/preview",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("assign_agency_cases"),
+			handlers.PreviewAgentAssignmentHandler)
+
+		agentRoutesV1.POST("/agencies/cases/auto-assign",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("assign_agency_cases"),
+			handlers.CommitAgentAssignmentHandler)
+
+		agentRoutesV1.PUT("/agencies/users/:user_id/assignment-profile",
+			middlewares.AuthMiddleware,
+			middlewares.PermissionMiddleware("assign_user_to_agency"),
+			handlers.UpdateAgentAssignmentProfileHandler)
+
 	}
 	router.GET("/health-check", handlers.Healthcheck)
 	router.OPTIONS("/*any", handlers.OptionsHandle)
@@ -1,8 +1,13 @@
 package services
 
 import (
+	"backend/constants"
 	"backend/models"
 	"backend/repository"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 func ListAllAgencies(env *models.Env) ([]models.Agency, error) {
@@ -16,23 +21,113 @@ func CreateAgency(env *models.Env, agency *models.Agency) error {
 	}
 
 	repo := repository.NewAgencyRepository(env.DbConn)
-	return repo.CreateAgency(agency)
+	if err := repo.CreateAgency(agency); err != nil {
+		return err
+	}
+
+	AuditEntity(env, constants.AUDIT_ENTITY_AGENCY, agency.ID)
+	AuditChange(env, nil, agencyAuditSnapshot(agency))
+	return nil
 }
 
 func DeleteAgency(env *models.Env, agencyID string) error {
 	repo := repository.NewAgencyRepository(env.DbConn)
-	return repo.DeleteAgency(agencyID)
+	agency, err := repo.GetAgency(agencyID)
+	if err != nil {
+		return err
+	}
+	if err := repo.DeleteAgency(agencyID); err != nil {
+		return err
+	}
+
+	if agency != nil {
+		AuditChange(env, agencyAuditSnapshot(agency), nil)
+	}
+	return nil
+}
+
+func agencyAuditSnapshot(agency *models.Agency) map[string]interface{} {
+	return map[string]interface{}{
+		"id":             agency.ID,
+		"agency_name":    agency.AgencyName,
+		"status":         agency.Status,
+		"agency_details": agency.AgencyDetails,
+	}
 }
 
 func AssignUserToAgency(env *models.Env, mapping *models.AgencyUserMap) error {
 	repo := repository.NewAgencyRepository(env.DbConn)
-	return repo.AssignUserToAgency(mapping)
+	if err := repo.AssignUserToAgency(mapping); err != nil {
+		return err
+	}
+
+	AuditEntity(env, constants.AUDIT_ENTITY_USER, mapping.UserID)
+	AuditChange(env, nil, map[string]interface{}{
+		"agency_id":   mapping.AgencyID,
+		"agency_role": mapping.AgencyRole,
+		"manager_id":  mapping.ManagerID,
+	})
+	return nil
 }
 
+// AssignCaseToUser assigns an unassigned case to an agent. A case waiting at
+// the agency moves to ASSIGNED_TO_AGENT; cases already being worked keep
+// their status. Cases held by another agent are moved with ReassignCases.
 func AssignCaseToUser(env *models.Env, mapping *models.CaseUserMap) error {
-	repo := repository.NewAgencyRepository(env.DbConn)
-	return repo.AssignCaseToUser(mapping)
+	now := time.Now()
+	*mapping = models.CaseUserMap{
+		CaseID:     mapping.CaseID,
+		UserID:     mapping.UserID,
+		AssignedAt: now,
+		AssignedBy: &env.AuthDtos.User.ID,
+		UpdatedAt:  now,
+	}
+
+	return env.DbConn.Transaction(func(tx *gorm.DB) error {
+		caseRepo := repository.NewCaseRepository(tx)
+		if _, err := caseRepo.GetCaseForUpdate(mapping.CaseID); err != nil {
+			return err
+		}
+		assignee, err := caseRepo.GetAssignedUserByCaseID(mapping.CaseID)
+		if err != nil {
+			return err
+		}
+		if assignee != nil {
+			return fmt.Errorf("%w: case is already assigned to %s", ErrInvalidAssignment, assignee.Username)
+		}
+
+		repo := repository.NewAgencyRepository(tx)
+		if err := repo.AssignCaseToUser(mapping); err != nil {
+			return err
+		}
+		if err := markCaseAssigned(tx, mapping.CaseID, &env.AuthDtos.User.ID); err != nil {
+			return err
+		}
+
+		AuditEntity(env, constants.AUDIT_ENTITY_CASE, mapping.CaseID)
+		AuditChange(env, nil, map[string]interface{}{"user_id": mapping.UserID})
+		return nil
+	})
 }
+
+// markCaseAssigned moves a case waiting at its agency to ASSIGNED_TO_AGENT
+// inside tx
+func markCaseAssigned(tx *gorm.DB, caseID string, actorID *string) error {
+	caseRepo := repository.NewCaseRepository(tx)
+	caseData, err := caseRepo.GetCaseForUpdate(caseID)
+	if err != nil {
+		return err
+	}
+	if caseData.CaseStatus != constants.CASE_STATUS_ALLOCATED_TO_AGENCY {
+		return nil
+	}
+	err = transitionCase(tx, caseData, constants.CASE_STATUS_ASSIGNED_TO_AGENT, constants.CASE_REASON_AGENT_ASSIGNMENT, "", actorID)
+	if err != nil {
+		return err
+	}
+	return caseRepo.UpdateCase(caseData)
+}
+
 func ListAgencyUsers(env *models.Env, agencyID string) ([]models.AgencyUserDetails, error) {
 	repo := repository.NewAgencyRepository(env.DbConn)
 	return repo.ListAgencyUsers(agencyID)
@@ -5,19 +5,17 @@ import (
 	"backend/repository"
 )
 
-func ListCases(env *models.Env, userID string) ([]models.Case, error) {
-	agencyRepository := repository.NewAgencyRepository(env.DbConn)
+// ListCases lists the cases allocated to the user's agency with their assigned agents
+func ListCases(env *models.Env, userID string, filter models.CaseFilter) ([]models.CaseWithAssignee, int64, error) {
+	caseRepository := repository.NewCaseRepository(env.DbConn)
 	userRepository := repository.NewUserRepository(env.DbConn)
 	agencyID, err := userRepository.GetUserAgencyID(userID)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	cases, err := agencyRepository.ListCases(agencyID)
-	if err != nil {
-		return nil, err
-	}
-	return cases, nil
+	filter.AgencyID = &agencyID
+	return caseRepository.ListCasesWithAssignee(filter)
 }
 
 func AssignCases(env *models.Env, agencyID string, caseIDs []string) ([]models.AgencyCaseMap, error) {
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"backend/constants"
+	"backend/models"
+	"backend/repository"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoAgency            = errors.New("user is not linked to an agency")
+	ErrUnknownStrategy     = errors.New("unknown assignment strategy")
+	ErrInvalidAgentProfile = errors.New("invalid agent assignment profile")
+	ErrInvalidAssignment   = errors.New("invalid assignment")
+)
+
+// AgentAssignmentRequest narrows a run of agent auto-assignment. AgencyID is
+// only honoured for users who can see every case; everyone else works on
+// their own agency.
+type AgentAssignmentRequest struct {
+	AgencyID string
+	Strategy string
+	CaseIDs  []string
+	Limit    int
+}
+
+// agentAssignmentAgency resolves the agency a user auto-assigns cases for
+func agentAssignmentAgency(env *models.Env, agencyID string) (string, error) {
+	if agencyID != "" && utils.HasPermission(env, "view_all_cases") {
+		return agencyID, nil
+	}
+	userRepo := repository.NewUserRepository(env.DbConn)
+	agencyID, err := userRepo.GetUserAgencyID(env.AuthDtos.User.ID)
+	if err != nil {
+		return "", err
+	}
+	if agencyID == "" {
+		return "", ErrNoAgency
+	}
+	return agencyID, nil
+}
+
+func UpdateAgentAssignmentProfile(env *models.Env, agencyID, userID string, maxOpenCases *int, territories []string) error {
+	if maxOpenCases != nil && *maxOpenCases < 0 {
+		return fmt.Errorf("%w: max_open_cases cannot be negative", ErrInvalidAgentProfile)
+	}
+	agencyID, err := agentAssignmentAgency(env, agencyID)
+	if err != nil {
+		return err
+	}
+	repo := repository.NewAgencyRepository(env.DbConn)
+	err = repo.UpdateAgentAssignmentProfile(agencyID, userID, maxOpenCases, territories)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: user is not an active member of the agency", ErrInvalidAgentProfile)
+	}
+	if err != nil {
+		return err
+	}
+
+	AuditEntity(env, constants.AUDIT_ENTITY_USER, userID)
+	AuditChange(env, nil, map[string]interface{}{"agency_id": agencyID, "max_open_cases": maxOpenCases, "territories": territories})
+	return nil
+}
+
+// assignableAgent is an agent taking part in a run
+type assignableAgent struct {
+	summary *models.AgentAssignmentSummary
+}
+
+func (a *assignableAgent) load() int {
+	return a.summary.OpenCases + a.summary.Assigned
+}
+
+func (a *assignableAgent) hasCapacity() bool {
+	return a.load() < a.summary.MaxOpenCases
+}
+
+func (a *assignableAgent) covers(pincode string) bool {
+	return pincode != "" && slices.ContainsFunc(a.summary.Territories, func(prefix string) bool {
+		return strings.HasPrefix(pincode, prefix)
+	})
+}
+
+// assignableAgents loads the active agents of the agency with their current
+// load and cap. Agency admins and other members without the agent role
+// never receive cases.
+func assignableAgents(db *gorm.DB, agencyID string) ([]*assignableAgent, error) {
+	repo := repository.NewAgencyRepository(db)
+	users, err := repo.ListAgencyUsersWithRole(agencyID, "agent")
+	if err != nil {
+		return nil, err
+	}
+
+	defaultCap := viper.GetInt("AGENT_MAX_OPEN_CASES")
+	if defaultCap <= 0 {
+		defaultCap = constants.DEFAULT_AGENT_MAX_OPEN_CASES
+	}
+
+	agents := []*assignableAgent{}
+	userIDs := []string{}
+	for _, user := range users {
+		maxOpenCases := defaultCap
+		if user.MaxOpenCases != nil {
+			maxOpenCases = *user.MaxOpenCases
+		}
+		territories := []string(user.Territories)
+		if territories == nil {
+			territories = []string{}
+		}
+		agents = append(agents, &assignableAgent{summary: &models.AgentAssignmentSummary{
+			UserID:       user.UserID,
+			Username:     user.Username,
+			MaxOpenCases: maxOpenCases,
+			Territories:  territories,
+		}})
+		userIDs = append(userIDs, user.UserID)
+	}
+	if len(agents) == 0 {
+		return agents, nil
+	}
+
+	counts, err := repo.CountOpenCasesByUser(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, agent := range agents {
+		agent.summary.OpenCases = counts[agent.summary.UserID]
+	}
+	return agents, nil
+}
+
+// agentPicker chooses the agent for each case in turn
+type agentPicker struct {
+	strategy string
+	agents   []*assignableAgent
+	next     int
+}
+
+// pick returns the agent for the case, or nil and the reason it stays
+// unassigned
+func (p *agentPicker) pick(caseData models.AllocationCase) (*assignableAgent, string) {
+	if len(p.agents) == 0 {
+		return nil, constants.AGENT_ASSIGNMENT_SKIP_NO_AGENTS
+	}
+
+	switch p.strategy {
+	case constants.AGENT_ASSIGNMENT_ROUND_ROBIN:
+		for i := range p.agents {
+			index := (p.next + i) % len(p.agents)
+			if p.agents[index].hasCapacity() {
+				p.next = index + 1
+				return p.agents[index], ""
+			}
+		}
+		return nil, constants.AGENT_ASSIGNMENT_SKIP_AGENTS_AT_CAPACITY
+
+	case constants.AGENT_ASSIGNMENT_TERRITORY:
+		covered := false
+		var chosen *assignableAgent
+		for _, agent := range p.agents {
+			if !agent.covers(caseData.Pincode) {
+				continue
+			}
+			covered = true
+			if agent.hasCapacity() && (chosen == nil || agent.load() < chosen.load()) {
+				chosen = agent
+			}
+		}
+		if !covered {
+			return nil, constants.AGENT_ASSIGNMENT_SKIP_NO_TERRITORY
+		}
+		if chosen == nil {
+			return nil, constants.AGENT_ASSIGNMENT_SKIP_AGENTS_AT_CAPACITY
+		}
+		return chosen, ""
+	}
+
+	var chosen *assignableAgent
+	for _, agent := range p.agents {
+		if agent.hasCapacity() && (chosen == nil || agent.load() < chosen.load()) {
+			chosen = agent
+		}
+	}
+	if chosen == nil {
+		return nil, constants.AGENT_ASSIGNMENT_SKIP_AGENTS_AT_CAPACITY
+	}
+	return chosen, ""
+}
+
+// PreviewAgentAssignment works out which agent each unassigned case of the
+// agency would go to without writing anything
+func PreviewAgentAssignment(env *models.Env, req AgentAssignmentRequest) (*models.AgentAssignmentPlan, error) {
+	if !slices.Contains(constants.AgentAssignmentStrategies, req.Strategy) {
+		return nil, fmt.Errorf("%w %q, expected one of %s", ErrUnknownStrategy, req.Strategy, strings.Join(constants.AgentAssignmentStrategies, ", "))
+	}
+	agencyID, err := agentAssignmentAgency(env, req.AgencyID)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := repository.NewAgencyRepository(env.DbConn)
+	cases, err := repo.ListUnassignedAgencyCases(agencyID, req.CaseIDs, allocationLimit(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+	agents, err := assignableAgents(env.DbConn, agencyID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &models.AgentAssignmentPlan{
+		Strategy:    req.Strategy,
+		Assignments: []models.AgentAssignment{},
+		Unassigned:  []models.AllocationSkip{},
+		Agents:      []models.AgentAssignmentSummary{},
+	}
+	picker := &agentPicker{strategy: req.Strategy, agents: agents}
+	for _, caseData := range cases {
+		agent, reason := picker.pick(caseData)
+		if agent == nil {
+			plan.Unassigned = append(plan.Unassigned, models.AllocationSkip{CaseID: caseData.ID, LoanID: caseData.LoanID, Reason: reason})
+			continue
+		}
+
+		agent.summary.Assigned++
+		plan.Assignments = append(plan.Assignments, models.AgentAssignment{
+			CaseID:   caseData.ID,
+			LoanID:   caseData.LoanID,
+			UserID:   agent.summary.UserID,
+			Username: agent.summary.Username,
+		})
+	}
+
+	for _, agent := range agents {
+		plan.Agents = append(plan.Agents, *agent.summary)
+	}
+	return plan, nil
+}
+
+// CommitAgentAssignment writes a previewed plan. Every case must still be
+// unassigned within the agency and every agent active and under their cap,
+// otherwise nothing is written.
+func CommitAgentAssignment(env *models.Env, agencyID string, assignments []models.AgentAssignment) error {
+	agencyID, err := agentAssignmentAgency(env, agencyID)
+	if err != nil {
+		return err
+	}
+
+	caseIDs := make([]string, 0, len(assignments))
+	for _, assignment := range assignments {
+		caseIDs = append(caseIDs, assignment.CaseID)
+	}
+
+	err = env.DbConn.Transaction(func(tx *gorm.DB) error {
+		caseRepo := repository.NewCaseRepository(tx)
+		for _, caseID := range caseIDs {
+			if _, err := caseRepo.GetCaseForUpdate(caseID); err != nil {
+				return err
+			}
+		}
+
+		repo := repository.NewAgencyRepository(tx)
+		available, err := repo.ListUnassignedAgencyCases(agencyID, caseIDs, len(caseIDs))
+		if err != nil {
+			return err
+		}
+		if len(available) != len(caseIDs) {
+			return fmt.Errorf("%w: %d of %d cases are no longer available for assignment", ErrInvalidAssignment, len(caseIDs)-len(available), len(caseIDs))
+		}
+
+		agents, err := assignableAgents(tx, agencyID)
+		if err != nil {
+			return err
+		}
+		agentsByID := make(map[string]*assignableAgent, len(agents))
+		for _, agent := range agents {
+			agentsByID[agent.summary.UserID] = agent
+		}
+
+		mappings := make([]models.CaseUserMap, 0, len(assignments))
+		now := time.Now()
+		for _, assignment := range assignments {
+			agent, ok := agentsByID[assignment.UserID]
+			if !ok {
+				return fmt.Errorf("%w: user %s is not an active agent of the agency", ErrInvalidAssignment, assignment.UserID)
+			}
+			if !agent.hasCapacity() {
+				return fmt.Errorf("%w: agent %s would exceed %d open cases", ErrInvalidAssignment, agent.summary.Username, agent.summary.MaxOpenCases)
+			}
+			agent.summary.Assigned++
+			mappings = append(mappings, models.CaseUserMap{
+				CaseID:     assignment.CaseID,
+				UserID:     assignment.UserID,
+				AssignedAt: now,
+				AssignedBy: &env.AuthDtos.User.ID,
+				UpdatedAt:  now,
+			})
+		}
+
+		if err := repo.CreateCaseUserMaps(mappings); err != nil {
+			return err
+		}
+		for _, caseID := range caseIDs {
+			if err := markCaseAssigned(tx, caseID, &env.AuthDtos.User.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	AuditEntity(env, constants.AUDIT_ENTITY_CASE, "")
+	AuditChange(env, nil, map[string]interface{}{"agency_id": agencyID, "assignments": assignments})
+	return nil
+}
@@ -0,0 +1,361 @@
+package services
+
+import (
+	"backend/constants"
+	"backend/models"
+	"backend/repository"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoLender              = errors.New("user is not linked to a lender")
+	ErrInvalidAllocation     = errors.New("invalid allocation")
+	ErrInvalidAllocationRule = errors.New("invalid allocation rule")
+)
+
+// AllocationRequest narrows a run of the allocation engine. CaseIDs limits
+// the run to those cases; UsePerformance scales each agency's share by its
+// recent collections.
+type AllocationRequest struct {
+	CaseIDs        []string
+	Limit          int
+	UsePerformance bool
+}
+
+func ListAllocationRules(env *models.Env) ([]models.AllocationRule, error) {
+	repo := repository.NewAllocationRepository(env.DbConn)
+	return repo.ListRules()
+}
+
+func SaveAllocationRule(env *models.Env, rule *models.AllocationRule) error {
+	if rule.Weight <= 0 {
+		return fmt.Errorf("%w: weight must be greater than zero", ErrInvalidAllocationRule)
+	}
+	if rule.Capacity != nil && *rule.Capacity < 0 {
+		return fmt.Errorf("%w: capacity cannot be negative", ErrInvalidAllocationRule)
+	}
+
+	agencyRepo := repository.NewAgencyRepository(env.DbConn)
+	agencies, err := agencyRepo.ListAllAgencies()
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(agencies, func(agency models.Agency) bool { return agency.ID == rule.AgencyID }) {
+		return fmt.Errorf("%w: agency not found", ErrInvalidAllocationRule)
+	}
+
+	repo := repository.NewAllocationRepository(env.DbConn)
+	before, err := repo.GetRuleByAgency(rule.AgencyID)
+	if err != nil {
+		return err
+	}
+	if err := repo.SaveRule(rule); err != nil {
+		return err
+	}
+
+	AuditChange(env, before, rule)
+	return nil
+}
+
+// allocationLenderScope limits users without view_all_cases to the cases of
+// their own lender
+func allocationLenderScope(env *models.Env) (*string, error) {
+	if utils.HasPermission(env, "view_all_cases") {
+		return nil, nil
+	}
+	userRepo := repository.NewUserRepository(env.DbConn)
+	lenderID, err := userRepo.GetUserLenderID(env.AuthDtos.User.ID)
+	if err != nil {
+		return nil, err
+	}
+	if lenderID == "" {
+		return nil, ErrNoLender
+	}
+	return &lenderID, nil
+}
+
+// allocationCandidate is an agency taking part in a run
+type allocationCandidate struct {
+	rule    models.AllocationRule
+	summary *models.AgencyAllocationSummary
+	share   float64
+}
+
+func (a *allocationCandidate) matches(caseData models.AllocationCase) bool {
+	rule := a.rule
+	if len(rule.DPDBuckets) > 0 && !slices.Contains(rule.DPDBuckets, caseData.DPDBucket) {
+		return false
+	}
+	if len(rule.LoanTypes) > 0 && !slices.ContainsFunc(rule.LoanTypes, func(loanType string) bool {
+		return strings.Contains(strings.ToLower(caseData.LoanDescription), strings.ToLower(loanType))
+	}) {
+		return false
+	}
+	if len(rule.Pincodes) > 0 && !slices.ContainsFunc(rule.Pincodes, func(prefix string) bool {
+		return caseData.Pincode != "" && strings.HasPrefix(caseData.Pincode, prefix)
+	}) {
+		return false
+	}
+	return true
+}
+
+func (a *allocationCandidate) hasCapacity() bool {
+	return a.rule.Capacity == nil || a.summary.OpenCases+a.summary.Allocated < *a.rule.Capacity
+}
+
+// PreviewAllocation works out which agency each unallocated case would go
+// to without writing anything. Every case goes to the matching agency with
+// capacity that is furthest below its share of this run; shares follow the
+// rule weights, scaled by recent collection rates when requested.
+func PreviewAllocation(env *models.Env, req AllocationRequest) (*models.AllocationPlan, error) {
+	lenderID, err := allocationLenderScope(env)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := repository.NewAllocationRepository(env.DbConn)
+	cases, err := repo.ListUnallocatedCases(lenderID, req.CaseIDs, allocationLimit(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := allocationCandidates(env.DbConn, req.UsePerformance)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &models.AllocationPlan{
+		Assignments: []models.AllocationAssignment{},
+		Unallocated: []models.AllocationSkip{},
+		Agencies:    []models.AgencyAllocationSummary{},
+	}
+	for _, caseData := range cases {
+		var chosen *allocationCandidate
+		matched := false
+		for _, candidate := range candidates {
+			if !candidate.matches(caseData) {
+				continue
+			}
+			matched = true
+			if !candidate.hasCapacity() {
+				continue
+			}
+			if chosen == nil || float64(candidate.summary.Allocated)/candidate.share < float64(chosen.summary.Allocated)/chosen.share {
+				chosen = candidate
+			}
+		}
+
+		if chosen == nil {
+			reason := constants.ALLOCATION_SKIP_NO_MATCHING_AGENCY
+			if matched {
+				reason = constants.ALLOCATION_SKIP_AT_CAPACITY
+			}
+			plan.Unallocated = append(plan.Unallocated, models.AllocationSkip{CaseID: caseData.ID, LoanID: caseData.LoanID, Reason: reason})
+			continue
+		}
+
+		chosen.summary.Allocated++
+		plan.Assignments = append(plan.Assignments, models.AllocationAssignment{
+			CaseID:     caseData.ID,
+			LoanID:     caseData.LoanID,
+			AgencyID:   chosen.rule.AgencyID,
+			AgencyName: chosen.summary.AgencyName,
+		})
+	}
+
+	for _, candidate := range candidates {
+		plan.Agencies = append(plan.Agencies, *candidate.summary)
+	}
+	return plan, nil
+}
+
+// allocationLimit caps the cases looked at in one run at ALLOCATION_MAX_CASES
+func allocationLimit(limit int) int {
+	maxCases := viper.GetInt("ALLOCATION_MAX_CASES")
+	if maxCases <= 0 {
+		maxCases = constants.DEFAULT_ALLOCATION_MAX_CASES
+	}
+	if limit <= 0 || limit > maxCases {
+		return maxCases
+	}
+	return limit
+}
+
+// allocationCandidates loads the agencies with an active rule and their
+// current load and share
+func allocationCandidates(db *gorm.DB, usePerformance bool) ([]*allocationCandidate, error) {
+	repo := repository.NewAllocationRepository(db)
+	rules, err := repo.ListActiveRules()
+	if err != nil {
+		return nil, err
+	}
+
+	lookbackDays := viper.GetInt("ALLOCATION_PERFORMANCE_LOOKBACK_DAYS")
+	if lookbackDays <= 0 {
+		lookbackDays = constants.DEFAULT_ALLOCATION_PERFORMANCE_LOOKBACK_DAYS
+	}
+	stats, err := repo.GetAgencyStats(time.Now().AddDate(0, 0, -lookbackDays))
+	if err != nil {
+		return nil, err
+	}
+
+	agencyRepo := repository.NewAgencyRepository(db)
+	agencies, err := agencyRepo.ListAllAgencies()
+	if err != nil {
+		return nil, err
+	}
+	agencyNames := map[string]string{}
+	for _, agency := range agencies {
+		agencyNames[agency.ID] = agency.AgencyName
+	}
+
+	factors := map[string]float64{}
+	if usePerformance {
+		factors = performanceFactors(stats)
+	}
+
+	candidates := []*allocationCandidate{}
+	for _, rule := range rules {
+		factor, ok := factors[rule.AgencyID]
+		if !ok {
+			factor = 1
+		}
+		candidates = append(candidates, &allocationCandidate{
+			rule:  rule,
+			share: rule.Weight * factor,
+			summary: &models.AgencyAllocationSummary{
+				AgencyID:          rule.AgencyID,
+				AgencyName:        agencyNames[rule.AgencyID],
+				OpenCases:         stats[rule.AgencyID].OpenCases,
+				Capacity:          rule.Capacity,
+				Weight:            rule.Weight,
+				PerformanceFactor: math.Round(factor*100) / 100,
+			},
+		})
+	}
+	return candidates, nil
+}
+
+// performanceFactors compares each agency's collection rate, collected over
+// collected plus outstanding, with the average rate. Agencies without
+// history are left out and count as average.
+func performanceFactors(stats map[string]models.AgencyAllocationStats) map[string]float64 {
+	rates := map[string]float64{}
+	total := 0.0
+	for agencyID, stat := range stats {
+		if stat.Collected+stat.Outstanding <= 0 {
+			continue
+		}
+		rates[agencyID] = stat.Collected / (stat.Collected + stat.Outstanding)
+		total += rates[agencyID]
+	}
+	if len(rates) == 0 || total == 0 {
+		return map[string]float64{}
+	}
+
+	average := total / float64(len(rates))
+	factors := make(map[string]float64, len(rates))
+	for agencyID, rate := range rates {
+		factor := rate / average
+		factors[agencyID] = math.Max(1.0/constants.ALLOCATION_MAX_PERFORMANCE_FACTOR, math.Min(factor, constants.ALLOCATION_MAX_PERFORMANCE_FACTOR))
+	}
+	return factors
+}
+
+// CommitAllocation writes a previewed plan. Every case must still be
+// unallocated and within the user's lender, and every agency must still
+// have an active rule matching the case and room under its capacity,
+// otherwise nothing is written.
+func CommitAllocation(env *models.Env, assignments []models.AllocationAssignment) error {
+	lenderID, err := allocationLenderScope(env)
+	if err != nil {
+		return err
+	}
+
+	caseIDs := make([]string, 0, len(assignments))
+	caseIDsByAgency := map[string][]string{}
+	for _, assignment := range assignments {
+		caseIDs = append(caseIDs, assignment.CaseID)
+		caseIDsByAgency[assignment.AgencyID] = append(caseIDsByAgency[assignment.AgencyID], assignment.CaseID)
+	}
+
+	agencyRepo := repository.NewAgencyRepository(env.DbConn)
+	agencies, err := agencyRepo.ListAllAgencies()
+	if err != nil {
+		return err
+	}
+	for agencyID := range caseIDsByAgency {
+		if !slices.ContainsFunc(agencies, func(agency models.Agency) bool { return agency.ID == agencyID }) {
+			return fmt.Errorf("%w: agency %s is not active", ErrInvalidAllocation, agencyID)
+		}
+	}
+
+	err = env.DbConn.Transaction(func(tx *gorm.DB) error {
+		repo := repository.NewAllocationRepository(tx)
+		available, err := repo.ListUnallocatedCases(lenderID, caseIDs, len(caseIDs))
+		if err != nil {
+			return err
+		}
+		if len(available) != len(caseIDs) {
+			return fmt.Errorf("%w: %d of %d cases are no longer available for allocation", ErrInvalidAllocation, len(caseIDs)-len(available), len(caseIDs))
+		}
+		candidates, err := allocationCandidates(tx, false)
+		if err != nil {
+			return err
+		}
+		if err := checkAllocationAssignments(assignments, available, candidates); err != nil {
+			return err
+		}
+
+		for agencyID, agencyCaseIDs := range caseIDsByAgency {
+			if err := allocateCases(tx, agencyID, agencyCaseIDs, &env.AuthDtos.User.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	AuditEntity(env, constants.AUDIT_ENTITY_CASE, "")
+	AuditChange(env, nil, map[string]interface{}{"case_ids_by_agency": caseIDsByAgency})
+	return nil
+}
+
+// checkAllocationAssignments applies the rules of the engine to a submitted
+// plan, since the rules or the agencies' load may have changed since it was
+// previewed
+func checkAllocationAssignments(assignments []models.AllocationAssignment, cases []models.AllocationCase, candidates []*allocationCandidate) error {
+	casesByID := make(map[string]models.AllocationCase, len(cases))
+	for _, caseData := range cases {
+		casesByID[caseData.ID] = caseData
+	}
+	candidatesByAgency := make(map[string]*allocationCandidate, len(candidates))
+	for _, candidate := range candidates {
+		candidatesByAgency[candidate.rule.AgencyID] = candidate
+	}
+
+	for _, assignment := range assignments {
+		candidate, ok := candidatesByAgency[assignment.AgencyID]
+		if !ok {
+			return fmt.Errorf("%w: agency %s has no active allocation rule", ErrInvalidAllocation, assignment.AgencyID)
+		}
+		if !candidate.matches(casesByID[assignment.CaseID]) {
+			return fmt.Errorf("%w: case %s does not match the rule of agency %s", ErrInvalidAllocation, assignment.CaseID, assignment.AgencyID)
+		}
+		if !candidate.hasCapacity() {
+			return fmt.Errorf("%w: agency %s is at capacity", ErrInvalidAllocation, assignment.AgencyID)
+		}
+		candidate.summary.Allocated++
+	}
+	return nil
+}
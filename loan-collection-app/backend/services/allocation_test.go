@@ -0,0 +1,122 @@
+package services
+
+import (
+	"backend/constants"
+	"backend/models"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestPerformanceFactors(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats map[string]models.AgencyAllocationStats
+		want  map[string]float64
+	}{
+		{
+			name:  "no history",
+			stats: map[string]models.AgencyAllocationStats{},
+			want:  map[string]float64{},
+		},
+		{
+			name: "nothing collected",
+			stats: map[string]models.AgencyAllocationStats{
+				"a": {Outstanding: 100},
+				"b": {Outstanding: 200},
+			},
+			want: map[string]float64{},
+		},
+		{
+			name: "relative to the average rate",
+			stats: map[string]models.AgencyAllocationStats{
+				"a": {Collected: 30, Outstanding: 70},
+				"b": {Collected: 10, Outstanding: 90},
+			},
+			want: map[string]float64{"a": 1.5, "b": 0.5},
+		},
+		{
+			name: "agencies without cases are left out",
+			stats: map[string]models.AgencyAllocationStats{
+				"a": {Collected: 20, Outstanding: 80},
+				"b": {OpenCases: 3},
+			},
+			want: map[string]float64{"a": 1},
+		},
+		{
+			name: "clamped to the maximum factor",
+			stats: map[string]models.AgencyAllocationStats{
+				"a": {Collected: 100},
+				"b": {Collected: 1, Outstanding: 999},
+				"c": {Collected: 1, Outstanding: 999},
+				"d": {Collected: 1, Outstanding: 999},
+				"e": {Collected: 1, Outstanding: 999},
+				"f": {Collected: 1, Outstanding: 999},
+			},
+			want: map[string]float64{
+				"a": constants.ALLOCATION_MAX_PERFORMANCE_FACTOR,
+				"b": 1.0 / constants.ALLOCATION_MAX_PERFORMANCE_FACTOR,
+				"c": 1.0 / constants.ALLOCATION_MAX_PERFORMANCE_FACTOR,
+				"d": 1.0 / constants.ALLOCATION_MAX_PERFORMANCE_FACTOR,
+				"e": 1.0 / constants.ALLOCATION_MAX_PERFORMANCE_FACTOR,
+				"f": 1.0 / constants.ALLOCATION_MAX_PERFORMANCE_FACTOR,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := performanceFactors(tt.stats)
+			if len(got) != len(tt.want) {
+				t.Fatalf("performanceFactors() = %v, want %v", got, tt.want)
+			}
+			for agencyID, want := range tt.want {
+				if math.Abs(got[agencyID]-want) > 1e-9 {
+					t.Errorf("factor of %s = %v, want %v", agencyID, got[agencyID], want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckAllocationAssignments(t *testing.T) {
+	capacity := 2
+	newCandidates := func() []*allocationCandidate {
+		return []*allocationCandidate{
+			{
+				rule:    models.AllocationRule{AgencyID: "a", DPDBuckets: []string{"31-60"}},
+				summary: &models.AgencyAllocationSummary{AgencyID: "a"},
+			},
+			{
+				rule:    models.AllocationRule{AgencyID: "b", Capacity: &capacity},
+				summary: &models.AgencyAllocationSummary{AgencyID: "b", OpenCases: 1},
+			},
+		}
+	}
+	cases := []models.AllocationCase{
+		{ID: "c1", DPDBucket: "31-60"},
+		{ID: "c2", DPDBucket: "61-90"},
+		{ID: "c3", DPDBucket: "61-90"},
+	}
+
+	tests := []struct {
+		name        string
+		assignments []models.AllocationAssignment
+		wantErr     bool
+	}{
+		{"matching and within capacity", []models.AllocationAssignment{{CaseID: "c1", AgencyID: "a"}, {CaseID: "c2", AgencyID: "b"}}, false},
+		{"case outside the rule", []models.AllocationAssignment{{CaseID: "c2", AgencyID: "a"}}, true},
+		{"agency without a rule", []models.AllocationAssignment{{CaseID: "c1", AgencyID: "z"}}, true},
+		{"over capacity", []models.AllocationAssignment{{CaseID: "c2", AgencyID: "b"}, {CaseID: "c3", AgencyID: "b"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAllocationAssignments(tt.assignments, cases, newCandidates())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkAllocationAssignments() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidAllocation) {
+				t.Errorf("error %v is not ErrInvalidAllocation", err)
+			}
+		})
+	}
+}
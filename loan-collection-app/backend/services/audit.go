@@ -0,0 +1,69 @@
+package services
+
+import (
+	"backend/constants"
+	"backend/models"
+	"backend/repository"
+	"encoding/json"
+)
+
+// auditEvent returns the audit event of the current request, or nil outside
+// an audited request, such as in commands and scheduled jobs
+func auditEvent(env *models.Env) *models.AuditEvent {
+	if env.RequestContext == nil {
+		return nil
+	}
+	event, _ := env.RequestContext[constants.AUDIT_EVENT_CONTEXT_KEY].(*models.AuditEvent)
+	return event
+}
+
+// AuditEntity names the entity the current request changes
+func AuditEntity(env *models.Env, entityType, entityID string) {
+	if event := auditEvent(env); event != nil {
+		event.EntityType = entityType
+		event.EntityID = entityID
+	}
+}
+
+// AuditChange records the state of the entity before and after the current
+// request. Pass nil for a side that does not exist, such as before a create.
+func AuditChange(env *models.Env, before, after interface{}) {
+	event := auditEvent(env)
+	if event == nil {
+		return
+	}
+	event.Before = auditJSON(env, before)
+	event.After = auditJSON(env, after)
+}
+
+func auditJSON(env *models.Env, value interface{}) []byte {
+	if value == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		env.Logger.Error("Error encoding audit snapshot: " + err.Error())
+		return nil
+	}
+	// A nil pointer to an entity is an absent side too
+	if string(data) == "null" {
+		return nil
+	}
+	return data
+}
+
+// RecordAuditEvent stores the audit event of a finished request on behalf of
+// the signed in user, if any
+func RecordAuditEvent(env *models.Env, event *models.AuditEvent) error {
+	if user := env.AuthDtos.User; user != nil {
+		event.ActorID = &user.ID
+		event.ActorUsername = user.Username
+	}
+	repo := repository.NewAuditEventRepository(env.DbConn)
+	return repo.CreateAuditEvent(event)
+}
+
+func ListAuditEvents(env *models.Env, filter models.AuditEventFilter) ([]models.AuditEvent, int64, error) {
+	repo := repository.NewAuditEventRepository(env.DbConn)
+	return repo.ListAuditEvents(filter)
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"backend/constants"
+	"backend/models"
+	"backend/repository"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+type AuthTokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
+}
+
+func accessTokenTTL() time.Duration {
+	minutes := viper.GetInt("ACCESS_TOKEN_TTL_MINUTES")
+	if minutes <= 0 {
+		minutes = constants.DEFAULT_ACCESS_TOKEN_TTL_MINUTES
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+func refreshTokenTTL() time.Duration {
+	hours := viper.GetInt("REFRESH_TOKEN_TTL_HOURS")
+	if hours <= 0 {
+		hours = constants.DEFAULT_REFRESH_TOKEN_TTL_HOURS
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+func hashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// issueTokens signs a new access token and stores a new refresh token for the user
+func issueTokens(env *models.Env, user *models.User) (*AuthTokens, error) {
+	ttl := accessTokenTTL()
+	accessToken, err := generateJWTToken(user, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(raw)
+
+	tokenRepo := repository.NewTokenRepository(env.RedisClient)
+	if err := tokenRepo.SaveRefreshToken(hashRefreshToken(refreshToken), user.ID, refreshTokenTTL()); err != nil {
+		return nil, err
+	}
+
+	return &AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(ttl.Seconds()),
+	}, nil
+}
+
+func generateJWTToken(user *models.User, ttl time.Duration) (string, error) {
+	jwtSecret := viper.GetString(constants.JWT_SECRET)
+	if jwtSecret == "" {
+		return "", fmt.Errorf("JWT secret is not configured")
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"jti":      uuid.NewString(),
+		"user_id":  user.ID,
+		"username": user.Username,
+		"email":    user.Email,
+		"iat":      now.Unix(),
+		"exp":      now.Add(ttl).Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(jwtSecret))
+}
+
+// RefreshTokens exchanges a refresh token for a new token pair. Every refresh
+// token is single use; presenting one twice means it leaked, so all of the
+// user's sessions are revoked.
+func RefreshTokens(env *models.Env, refreshToken string) (*AuthTokens, error) {
+	tokenRepo := repository.NewTokenRepository(env.RedisClient)
+	tokenHash := hashRefreshToken(refreshToken)
+
+	userID, err := tokenRepo.ConsumeRefreshToken(tokenHash, refreshTokenTTL())
+	if err != nil {
+		return nil, err
+	}
+	if userID == "" {
+		reuserID, err := tokenRepo.GetRefreshTokenReuser(tokenHash)
+		if err != nil {
+			return nil, err
+		}
+		if reuserID != "" {
+			if err := LogoutAll(env, reuserID); err != nil {
+				return nil, err
+			}
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	userRepo := repository.NewUserRepository(env.DbConn)
+	user, err := userRepo.FindByID(userID)
+	if err != nil || user == nil || !user.IsActive {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return issueTokens(env, user)
+}
+
+// Logout revokes the access token in use and, when given, its refresh token
+func Logout(env *models.Env, userID, jti string, expiresAt time.Time, refreshToken string) error {
+	tokenRepo := repository.NewTokenRepository(env.RedisClient)
+	if err := tokenRepo.RevokeAccessToken(jti, time.Until(expiresAt)); err != nil {
+		return err
+	}
+	if refreshToken == "" {
+		return nil
+	}
+	return tokenRepo.DeleteRefreshToken(hashRefreshToken(refreshToken), userID)
+}
+
+// LogoutAll revokes every refresh token of the user and every access token
+// issued to them so far
+func LogoutAll(env *models.Env, userID string) error {
+	tokenRepo := repository.NewTokenRepository(env.RedisClient)
+	if err := tokenRepo.DeleteUserRefreshTokens(userID); err != nil {
+		return err
+	}
+	return tokenRepo.RevokeUserTokensBefore(userID, time.Now(), accessTokenTTL())
+}
+
+// IsAccessTokenRevoked reports whether the token was logged out, either on
+// its own or by revoking all of the user's sessions after it was issued
+func IsAccessTokenRevoked(env *models.Env, userID, jti string, issuedAt time.Time) (bool, error) {
+	tokenRepo := repository.NewTokenRepository(env.RedisClient)
+	revoked, err := tokenRepo.IsAccessTokenRevoked(jti)
+	if err != nil || revoked {
+		return revoked, err
+	}
+
+	revokedBefore, err := tokenRepo.GetUserTokensRevokedBefore(userID)
+	if err != nil {
+		return false, err
+	}
+	// iat only has second precision, so a token issued in the same second as
+	// the revocation may predate it and is treated as revoked
+	return issuedAt.Unix() <= revokedBefore, nil
+}
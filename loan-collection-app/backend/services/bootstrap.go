@@ -0,0 +1,113 @@
+package services
+
+import (
+	"backend/constants"
+	"backend/models"
+	"backend/repository"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+type BootstrapResult struct {
+	CreatedRoles  []string
+	AdminCreated  bool
+	AdminAssigned bool
+}
+
+// Bootstrap creates the roles of constants.RolePermissionsMap with their
+// default permissions and an initial admin user. It is safe to run
+// repeatedly: existing roles, grants and users are left as they are and only
+// missing pieces are added.
+func Bootstrap(env *models.Env, adminUsername, adminPassword string) (*BootstrapResult, error) {
+	if adminUsername == "" {
+		return nil, errors.New("admin username is required")
+	}
+
+	result := &BootstrapResult{CreatedRoles: []string{}}
+	err := env.DbConn.Transaction(func(tx *gorm.DB) error {
+		roleRepo := repository.NewRoleRepository(tx)
+		userRepo := repository.NewUserRepository(tx)
+
+		for _, roleName := range slices.Sorted(maps.Keys(constants.RolePermissionsMap)) {
+			_, err := roleRepo.GetRoleByName(roleName)
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			role, err := roleRepo.CreateRole(roleName, "")
+			if err != nil {
+				return err
+			}
+			if err := grantDefaultPermissions(tx, role); err != nil {
+				return err
+			}
+			result.CreatedRoles = append(result.CreatedRoles, roleName)
+		}
+
+		admin, err := userRepo.FindByUsername(adminUsername)
+		if err != nil {
+			return err
+		}
+		if admin == nil {
+			if len(adminPassword) < 8 {
+				return fmt.Errorf("password must be at least 8 characters long")
+			}
+			admin, err = userRepo.CreateUser(adminUsername, adminPassword)
+			if err != nil {
+				return err
+			}
+			result.AdminCreated = true
+		}
+
+		userRoles, err := roleRepo.GetRolesByUser(admin.ID)
+		if err != nil {
+			return err
+		}
+		for _, roleName := range []string{"admin", "default"} {
+			hasRole := slices.ContainsFunc(userRoles, func(role models.Role) bool {
+				return role.RoleName == roleName
+			})
+			if hasRole {
+				continue
+			}
+			role, err := roleRepo.GetRoleByName(roleName)
+			if err != nil {
+				return err
+			}
+			if err := roleRepo.AssignRoleToUser(admin.ID, role.ID); err != nil {
+				return err
+			}
+			result.AdminAssigned = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// grantDefaultPermissions gives a newly created built-in role the
+// permissions listed for it in constants.RolePermissionsMap
+func grantDefaultPermissions(tx *gorm.DB, role *models.Role) error {
+	names := constants.RolePermissionsMap[role.RoleName]
+	permissionRepo := repository.NewPermissionRepository(tx)
+	if err := permissionRepo.CreateMissingPermissions(names); err != nil {
+		return err
+	}
+	permissions, err := permissionRepo.GetPermissionsByNames(names)
+	if err != nil {
+		return err
+	}
+	permissionIDs := make([]string, len(permissions))
+	for i, permission := range permissions {
+		permissionIDs[i] = permission.ID
+	}
+	return permissionRepo.AttachPermissionsToRole(role.ID, permissionIDs)
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"backend/models"
+	"backend/repository"
+	"backend/utils"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CanAccessCase reports whether the authenticated user may see a case: it
+// must be assigned to them, to their agency for agency admins, or to their
+// lender for bank admins. Users with view_all_cases see every case.
+func CanAccessCase(env *models.Env, caseID string) (bool, error) {
+	if _, err := uuid.Parse(caseID); err != nil {
+		return false, nil
+	}
+
+	repo := repository.NewCaseRepository(env.DbConn)
+	if utils.HasPermission(env, "view_all_cases") {
+		_, err := repo.GetCase(caseID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	return repo.IsCaseAccessible(
+		caseID,
+		env.AuthDtos.User.ID,
+		utils.HasPermission(env, "view_agency_cases"),
+		utils.HasPermission(env, "view_lender_cases"),
+	)
+}
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"backend/constants"
+	"backend/models"
+	"backend/repository"
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// lockCases loads the cases for update in ID order, so concurrent moves of
+// overlapping cases wait for each other instead of deadlocking
+func lockCases(tx *gorm.DB, caseIDs []string) ([]*models.Case, error) {
+	caseIDs = slices.Clone(caseIDs)
+	slices.Sort(caseIDs)
+	caseIDs = slices.Compact(caseIDs)
+
+	repo := repository.NewCaseRepository(tx)
+	cases := make([]*models.Case, 0, len(caseIDs))
+	for _, caseID := range caseIDs {
+		caseData, err := repo.GetCaseForUpdate(caseID)
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, caseData)
+	}
+	return cases, nil
+}
+
+// lockAllocatedCases locks the cases and checks each one is allocated to an
+// agency and, when lenderID is set, belongs to that lender. It returns the
+// cases and the agency holding each one.
+func lockAllocatedCases(tx *gorm.DB, caseIDs []string, lenderID *string) ([]*models.Case, map[string]string, error) {
+	cases, err := lockCases(tx, caseIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]string, 0, len(cases))
+	for _, caseData := range cases {
+		if lenderID != nil && (caseData.LenderID == nil || *caseData.LenderID != *lenderID) {
+			return nil, nil, fmt.Errorf("%w: case %s does not belong to your lender", ErrInvalidAssignment, caseData.ID)
+		}
+		ids = append(ids, caseData.ID)
+	}
+
+	caseRepo := repository.NewCaseRepository(tx)
+	agencyIDs, err := caseRepo.GetAgencyIDsByCases(ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, caseData := range cases {
+		if _, ok := agencyIDs[caseData.ID]; !ok {
+			return nil, nil, fmt.Errorf("%w: case %s is not allocated to an agency", ErrInvalidAssignment, caseData.ID)
+		}
+	}
+	return cases, agencyIDs, nil
+}
+
+// RecallCases pulls the cases back from their agencies and agents. They
+// return to NEW, ready to be allocated again.
+func RecallCases(env *models.Env, caseIDs []string, remarks string) error {
+	lenderID, err := allocationLenderScope(env)
+	if err != nil {
+		return err
+	}
+	actorID := &env.AuthDtos.User.ID
+
+	var agencyIDs map[string]string
+	err = env.DbConn.Transaction(func(tx *gorm.DB) error {
+		cases, heldBy, err := lockAllocatedCases(tx, caseIDs, lenderID)
+		if err != nil {
+			return err
+		}
+		agencyIDs = heldBy
+
+		ids := make([]string, 0, len(cases))
+		caseRepo := repository.NewCaseRepository(tx)
+		for _, caseData := range cases {
+			if err := transitionCase(tx, caseData, constants.CASE_STATUS_NEW, constants.CASE_REASON_RECALLED, remarks, actorID); err != nil {
+				return err
+			}
+			if err := caseRepo.UpdateCase(caseData); err != nil {
+				return err
+			}
+			ids = append(ids, caseData.ID)
+		}
+
+		repo := repository.NewCaseAssignmentRepository(tx)
+		if err := repo.EndUserMappings(ids, actorID, constants.UNASSIGN_REASON_RECALLED, remarks); err != nil {
+			return err
+		}
+		return repo.EndAgencyMappings(ids, actorID, constants.UNASSIGN_REASON_RECALLED, remarks)
+	})
+	if err != nil {
+		return err
+	}
+
+	AuditEntity(env, constants.AUDIT_ENTITY_CASE, "")
+	AuditChange(env, map[string]interface{}{"agency_ids": agencyIDs}, map[string]interface{}{"remarks": remarks})
+	return nil
+}
+
+// ReallocateCases moves the cases from their current agencies to another
+// one. They leave their agents and wait at the new agency as
+// ALLOCATED_TO_AGENCY.
+func ReallocateCases(env *models.Env, caseIDs []string, agencyID, remarks string) error {
+	lenderID, err := allocationLenderScope(env)
+	if err != nil {
+		return err
+	}
+	agencyRepo := repository.NewAgencyRepository(env.DbConn)
+	agencies, err := agencyRepo.ListAllAgencies()
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(agencies, func(agency models.Agency) bool { return agency.ID == agencyID }) {
+		return fmt.Errorf("%w: agency %s is not active", ErrInvalidAssignment, agencyID)
+	}
+	actorID := &env.AuthDtos.User.ID
+
+	var previousAgencyIDs map[string]string
+	err = env.DbConn.Transaction(func(tx *gorm.DB) error {
+		cases, agencyIDs, err := lockAllocatedCases(tx, caseIDs, lenderID)
+		if err != nil {
+			return err
+		}
+		previousAgencyIDs = agencyIDs
+
+		ids := make([]string, 0, len(cases))
+		caseRepo := repository.NewCaseRepository(tx)
+		for _, caseData := range cases {
+			if agencyIDs[caseData.ID] == agencyID {
+				return fmt.Errorf("%w: case %s is already with the agency", ErrInvalidAssignment, caseData.ID)
+			}
+			if caseData.CaseStatus != constants.CASE_STATUS_ALLOCATED_TO_AGENCY {
+				err := transitionCase(tx, caseData, constants.CASE_STATUS_ALLOCATED_TO_AGENCY, constants.CASE_REASON_AGENCY_ALLOCATION, remarks, actorID)
+				if err != nil {
+					return err
+				}
+				if err := caseRepo.UpdateCase(caseData); err != nil {
+					return err
+				}
+			}
+			ids = append(ids, caseData.ID)
+		}
+
+		repo := repository.NewCaseAssignmentRepository(tx)
+		if err := repo.EndUserMappings(ids, actorID, constants.UNASSIGN_REASON_REALLOCATED, remarks); err != nil {
+			return err
+		}
+		if err := repo.EndAgencyMappings(ids, actorID, constants.UNASSIGN_REASON_REALLOCATED, remarks); err != nil {
+			return err
+		}
+		return caseRepo.AssignCasesToAgency(agencyID, ids, actorID)
+	})
+	if err != nil {
+		return err
+	}
+
+	AuditEntity(env, constants.AUDIT_ENTITY_CASE, "")
+	AuditChange(env, map[string]interface{}{"agency_ids": previousAgencyIDs}, map[string]interface{}{"agency_id": agencyID, "remarks": remarks})
+	return nil
+}
+
+// lockAgencyCases locks the cases and checks each one is allocated to the
+// agency
+func lockAgencyCases(tx *gorm.DB, agencyID string, caseIDs []string) ([]*models.Case, error) {
+	cases, agencyIDs, err := lockAllocatedCases(tx, caseIDs, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, caseData := range cases {
+		if agencyIDs[caseData.ID] != agencyID {
+			return nil, fmt.Errorf("%w: case %s is not allocated to your agency", ErrInvalidAssignment, caseData.ID)
+		}
+	}
+	return cases, nil
+}
+
+// RecallAgentCases takes the cases away from their agents. They stay with
+// the agency as ALLOCATED_TO_AGENCY.
+func RecallAgentCases(env *models.Env, agencyID string, caseIDs []string, remarks string) error {
+	agencyID, err := agentAssignmentAgency(env, agencyID)
+	if err != nil {
+		return err
+	}
+	actorID := &env.AuthDtos.User.ID
+
+	var previousUserIDs map[string]string
+	err = env.DbConn.Transaction(func(tx *gorm.DB) error {
+		cases, err := lockAgencyCases(tx, agencyID, caseIDs)
+		if err != nil {
+			return err
+		}
+
+		ids := make([]string, 0, len(cases))
+		for _, caseData := range cases {
+			ids = append(ids, caseData.ID)
+		}
+		repo := repository.NewCaseAssignmentRepository(tx)
+		userIDs, err := repo.GetActiveUserIDs(ids)
+		if err != nil {
+			return err
+		}
+
+		caseRepo := repository.NewCaseRepository(tx)
+		previousUserIDs = userIDs
+		for _, caseData := range cases {
+			if _, ok := userIDs[caseData.ID]; !ok {
+				return fmt.Errorf("%w: case %s is not assigned to an agent", ErrInvalidAssignment, caseData.ID)
+			}
+			err := transitionCase(tx, caseData, constants.CASE_STATUS_ALLOCATED_TO_AGENCY, constants.CASE_REASON_RECALLED, remarks, actorID)
+			if err != nil {
+				return err
+			}
+			if err := caseRepo.UpdateCase(caseData); err != nil {
+				return err
+			}
+		}
+		return repo.EndUserMappings(ids, actorID, constants.UNASSIGN_REASON_RECALLED, remarks)
+	})
+	if err != nil {
+		return err
+	}
+
+	AuditEntity(env, constants.AUDIT_ENTITY_CASE, "")
+	AuditChange(env, map[string]interface{}{"user_ids": previousUserIDs}, map[string]interface{}{"agency_id": agencyID, "remarks": remarks})
+	return nil
+}
+
+// ReassignCases moves the cases to another agent of the agency. Cases being
+// worked keep their status; cases waiting at the agency move to
+// ASSIGNED_TO_AGENT.
+func ReassignCases(env *models.Env, agencyID string, caseIDs []string, userID, remarks string) error {
+	agencyID, err := agentAssignmentAgency(env, agencyID)
+	if err != nil {
+		return err
+	}
+	actorID := &env.AuthDtos.User.ID
+
+	var previousUserIDs map[string]string
+	err = env.DbConn.Transaction(func(tx *gorm.DB) error {
+		cases, err := lockAgencyCases(tx, agencyID, caseIDs)
+		if err != nil {
+			return err
+		}
+
+		agents, err := assignableAgents(tx, agencyID)
+		if err != nil {
+			return err
+		}
+		index := slices.IndexFunc(agents, func(agent *assignableAgent) bool { return agent.summary.UserID == userID })
+		if index < 0 {
+			return fmt.Errorf("%w: user %s is not an active agent of the agency", ErrInvalidAssignment, userID)
+		}
+		agent := agents[index]
+
+		ids := make([]string, 0, len(cases))
+		for _, caseData := range cases {
+			ids = append(ids, caseData.ID)
+		}
+		repo := repository.NewCaseAssignmentRepository(tx)
+		userIDs, err := repo.GetActiveUserIDs(ids)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		previousUserIDs = userIDs
+		mappings := make([]models.CaseUserMap, 0, len(ids))
+		for _, caseID := range ids {
+			if userIDs[caseID] == userID {
+				return fmt.Errorf("%w: case %s is already assigned to %s", ErrInvalidAssignment, caseID, agent.summary.Username)
+			}
+			if !agent.hasCapacity() {
+				return fmt.Errorf("%w: agent %s would exceed %d open cases", ErrInvalidAssignment, agent.summary.Username, agent.summary.MaxOpenCases)
+			}
+			agent.summary.Assigned++
+			mappings = append(mappings, models.CaseUserMap{
+				CaseID:     caseID,
+				UserID:     userID,
+				AssignedAt: now,
+				AssignedBy: actorID,
+				UpdatedAt:  now,
+			})
+		}
+
+		if err := repo.EndUserMappings(ids, actorID, constants.UNASSIGN_REASON_REASSIGNED, remarks); err != nil {
+			return err
+		}
+		agencyRepo := repository.NewAgencyRepository(tx)
+		if err := agencyRepo.CreateCaseUserMaps(mappings); err != nil {
+			return err
+		}
+		for _, caseID := range ids {
+			if err := markCaseAssigned(tx, caseID, actorID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	AuditEntity(env, constants.AUDIT_ENTITY_CASE, "")
+	AuditChange(env, map[string]interface{}{"user_ids": previousUserIDs}, map[string]interface{}{"user_id": userID, "case_ids": caseIDs, "remarks": remarks})
+	return nil
+}
+
+func GetCaseAssignmentHistory(env *models.Env, caseID string) ([]models.CaseAssignmentRecord, error) {
+	repo := repository.NewCaseAssignmentRepository(env.DbConn)
+	return repo.ListAssignmentHistory(caseID)
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"backend/constants"
+	"backend/models"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// caseRowParser turns upload rows into cases using the columns found in the
+// header row, collecting a reason for every field it cannot accept
+type caseRowParser struct {
+	columns         map[string]int
+	requiredColumns []string
+	row             int
+	errors          []models.ImportRowError
+}
+
+func newCaseRowParser(header []string) (*caseRowParser, error) {
+	columns, err := mapImportColumns(header, caseImportColumnAliases(), constants.CaseImportRequiredColumns)
+	if err != nil {
+		return nil, err
+	}
+	return &caseRowParser{columns: columns, requiredColumns: constants.CaseImportRequiredColumns}, nil
+}
+
+// mapImportColumns finds the index of every known field in a header row,
+// failing when a required one is missing
+func mapImportColumns(header []string, aliases map[string][]string, required []string) (map[string]int, error) {
+	columns := map[string]int{}
+	for index, name := range header {
+		name = normaliseHeader(name)
+		for field, fieldAliases := range aliases {
+			if _, found := columns[field]; found {
+				continue
+			}
+			if slices.Contains(fieldAliases, name) {
+				columns[field] = index
+				break
+			}
+		}
+	}
+
+	missing := []string{}
+	for _, field := range required {
+		if _, found := columns[field]; !found {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required columns: %s", strings.Join(missing, ", "))
+	}
+	return columns, nil
+}
+
+// parse converts one record. The customer is nil when the upload carries no
+// customer details for the row.
+func (p *caseRowParser) parse(row int, record []string) (*models.Case, *models.Customer, []models.ImportRowError) {
+	p.row = row
+	p.errors = nil
+
+	case_ := &models.Case{
+		LoanID:                 p.required(record, "loan_id"),
+		ExternalCustomerID:     p.required(record, "external_customer_id"),
+		EMIAmount:              p.amount(record, "emi_amount"),
+		PrincipalOutstanding:   p.amount(record, "principal_outstanding"),
+		InterestOutstanding:    p.amount(record, "interest_outstanding"),
+		CaseStatus:             constants.CASE_STATUS_NEW,
+		EMIDate:                p.date(record, "emi_date"),
+		DPDBucket:              p.value(record, "dpd_bucket"),
+		DPD:                    p.count(record, "dpd"),
+		DisbursalDate:          p.date(record, "disbursal_date"),
+		InsuranceActive:        p.boolean(record, "insurance_active"),
+		LoanDescription:        p.value(record, "loan_description"),
+		EMIsPaidTillDate:       p.count(record, "emis_paid_till_date"),
+		EMIsPending:            p.count(record, "emis_pending"),
+		BounceCharges:          p.amount(record, "bounce_charges"),
+		NachPresentationStatus: p.value(record, "nach_presentation_status"),
+		CreatedAt:              time.Now(),
+		UpdatedAt:              time.Now(),
+	}
+
+	return case_, p.customer(case_.ExternalCustomerID, record), p.errors
+}
+
+// customer reads the customer columns of a row.
+// Several phone numbers can be given separated by "|".
+func (p *caseRowParser) customer(externalCustomerID string, record []string) *models.Customer {
+	customer := &models.Customer{
+		ExternalCustomerID: externalCustomerID,
+		Name:               p.value(record, "customer_name"),
+		Pincode:            p.value(record, "customer_pincode"),
+		Phones:             []string{},
+		Addresses:          []models.CustomerAddress{},
+	}
+	for _, phone := range strings.Split(p.value(record, "customer_phones"), "|") {
+		if phone = strings.TrimSpace(phone); phone != "" {
+			customer.Phones = append(customer.Phones, phone)
+		}
+	}
+	if email := p.value(record, "customer_email"); email != "" {
+		customer.Email = &email
+	}
+	if address := p.value(record, "customer_address"); address != "" {
+		customer.Addresses = append(customer.Addresses, models.CustomerAddress{
+			Address: address,
+			City:    p.value(record, "customer_city"),
+			State:   p.value(record, "customer_state"),
+			Pincode: customer.Pincode,
+		})
+	}
+
+	if customer.Name == "" && customer.Email == nil && customer.Pincode == "" &&
+		len(customer.Phones) == 0 && len(customer.Addresses) == 0 {
+		return nil
+	}
+	return customer
+}
+
+func (p *caseRowParser) fail(field, reason string) {
+	p.errors = append(p.errors, models.ImportRowError{Row: p.row, Field: field, Reason: reason})
+}
+
+func (p *caseRowParser) value(record []string, field string) string {
+	index, found := p.columns[field]
+	if !found || index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
+
+func (p *caseRowParser) isRequired(field string) bool {
+	return slices.Contains(p.requiredColumns, field)
+}
+
+func (p *caseRowParser) required(record []string, field string) string {
+	value := p.value(record, field)
+	if value == "" {
+		p.fail(field, "value is required")
+	}
+	return value
+}
+
+func (p *caseRowParser) amount(record []string, field string) float64 {
+	value := p.value(record, field)
+	if value == "" {
+		if p.isRequired(field) {
+			p.fail(field, "value is required")
+		}
+		return 0
+	}
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", ""), 64)
+	if err != nil {
+		p.fail(field, fmt.Sprintf("%q is not a valid amount", value))
+		return 0
+	}
+	if amount < 0 {
+		p.fail(field, "amount cannot be negative")
+	}
+	return amount
+}
+
+func (p *caseRowParser) count(record []string, field string) int {
+	value := p.value(record, field)
+	if value == "" {
+		if p.isRequired(field) {
+			p.fail(field, "value is required")
+		}
+		return 0
+	}
+	count, err := strconv.Atoi(value)
+	if err != nil {
+		p.fail(field, fmt.Sprintf("%q is not a whole number", value))
+		return 0
+	}
+	if count < 0 {
+		p.fail(field, "value cannot be negative")
+	}
+	return count
+}
+
+func (p *caseRowParser) date(record []string, field string) time.Time {
+	value := p.value(record, field)
+	if value == "" {
+		if p.isRequired(field) {
+			p.fail(field, "value is required")
+		}
+		return time.Time{}
+	}
+	for _, layout := range constants.CaseImportDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date
+		}
+	}
+	p.fail(field, fmt.Sprintf("%q is not a valid date, expected YYYY-MM-DD", value))
+	return time.Time{}
+}
+
+func (p *caseRowParser) boolean(record []string, field string) bool {
+	value := strings.ToLower(p.value(record, field))
+	switch value {
+	case "true", "yes", "y", "1":
+		return true
+	case "false", "no", "n", "0", "":
+		return false
+	}
+	p.fail(field, fmt.Sprintf("%q is not a valid boolean", value))
+	return false
+}
+
+// caseImportColumnAliases merges the configured aliases into the defaults
+func caseImportColumnAliases() map[string][]string {
+	aliases := map[string][]string{}
+	for field, names := range constants.CaseImportColumnAliases {
+		aliases[field] = slices.Clone(names)
+	}
+	for field, names := range viper.GetStringMapStringSlice("CASE_IMPORT_COLUMN_ALIASES") {
+		for _, name := range names {
+			aliases[field] = append(aliases[field], normaliseHeader(name))
+		}
+	}
+	return aliases
+}
+
+func normaliseHeader(name string) string {
+	name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+}
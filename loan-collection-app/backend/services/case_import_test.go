@@ -0,0 +1,124 @@
+package services
+
+import (
+	"encoding/csv"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewCaseRowParser(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		columns map[string]int
+		wantErr string
+	}{
+		{
+			name:    "canonical names",
+			header:  "loan_id,external_customer_id,emi_amount,principal_outstanding,emi_date,dpd",
+			columns: map[string]int{"loan_id": 0, "external_customer_id": 1, "emi_amount": 2, "principal_outstanding": 3, "emi_date": 4, "dpd": 5},
+		},
+		{
+			name:    "aliases, spacing, case and byte order mark",
+			header:  "\ufeffLoan No,Cust-ID,EMI,POS,Due Date,Days Past Due,Mobile",
+			columns: map[string]int{"loan_id": 0, "external_customer_id": 1, "emi_amount": 2, "principal_outstanding": 3, "emi_date": 4, "dpd": 5, "customer_phones": 6},
+		},
+		{
+			name:    "first matching column wins",
+			header:  "loan_id,loan_no,external_customer_id,emi_amount,principal_outstanding,emi_date,dpd",
+			columns: map[string]int{"loan_id": 0, "external_customer_id": 2},
+		},
+		{
+			name:    "missing required columns",
+			header:  "loan_id,emi_amount,emi_date",
+			wantErr: "missing required columns: external_customer_id, principal_outstanding, dpd",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := newCaseRowParser(strings.Split(tt.header, ","))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("newCaseRowParser() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newCaseRowParser() error = %v", err)
+			}
+			for field, index := range tt.columns {
+				if got, found := parser.columns[field]; !found || got != index {
+					t.Errorf("column of %s = %d (found %v), want %d", field, got, found, index)
+				}
+			}
+		})
+	}
+}
+
+func TestCaseRowParserParse(t *testing.T) {
+	header := "loan_id,customer_id,emi,pos,emi_date,dpd,insurance,bounce_charges,name,phone,address,pincode"
+	parser, err := newCaseRowParser(strings.Split(header, ","))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		record       string
+		wantFields   []string
+		wantCustomer bool
+	}{
+		{"valid row", "L1,C1,\"2,500.50\",10000,2026-01-05,12,yes,0,Asha,98450|  |98451,MG Road,560001", nil, true},
+		{"other date layouts", "L1,C1,2500,10000,05/01/2026,12,no,,,,,", nil, false},
+		{"required values missing", ",,,,,,,,,,,", []string{"loan_id", "external_customer_id", "emi_amount", "principal_outstanding", "emi_date", "dpd"}, false},
+		{"malformed values", "L1,C1,abc,-5,2026-13-01,1.5,maybe,x,,,,", []string{"emi_amount", "principal_outstanding", "emi_date", "dpd", "insurance_active", "bounce_charges"}, false},
+		{"negative count", "L1,C1,100,100,2026-01-05,-1,,,,,,", []string{"dpd"}, false},
+		{"short record", "L1,C1,100,100,2026-01-05", []string{"dpd"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caseData, customer, rowErrors := parser.parse(7, parseCSVLine(t, tt.record))
+
+			fields := []string{}
+			for _, rowErr := range rowErrors {
+				if rowErr.Row != 7 {
+					t.Errorf("error %v reported on row %d, want 7", rowErr, rowErr.Row)
+				}
+				fields = append(fields, rowErr.Field)
+			}
+			if !slices.Equal(fields, append([]string{}, tt.wantFields...)) {
+				t.Errorf("failed fields = %v, want %v", fields, tt.wantFields)
+			}
+			if (customer != nil) != tt.wantCustomer {
+				t.Errorf("customer = %v, want present %v", customer, tt.wantCustomer)
+			}
+			if len(rowErrors) == 0 && !caseData.EMIDate.Equal(time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("emi date = %v, want 2026-01-05", caseData.EMIDate)
+			}
+		})
+	}
+
+	t.Run("values of a valid row", func(t *testing.T) {
+		caseData, customer, _ := parser.parse(2, parseCSVLine(t, "L1,C1,\"2,500.50\",10000,2026-01-05,12,yes,0,Asha,98450|  |98451,MG Road,560001"))
+		if caseData.EMIAmount != 2500.5 || caseData.DPD != 12 || !caseData.InsuranceActive {
+			t.Errorf("case = %+v", caseData)
+		}
+		if !slices.Equal(customer.Phones, []string{"98450", "98451"}) {
+			t.Errorf("phones = %v, want [98450 98451]", customer.Phones)
+		}
+		if len(customer.Addresses) != 1 || customer.Addresses[0].Pincode != "560001" {
+			t.Errorf("addresses = %+v", customer.Addresses)
+		}
+	})
+}
+
+func parseCSVLine(t *testing.T, line string) []string {
+	t.Helper()
+	record, err := csv.NewReader(strings.NewReader(line)).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return record
+}
@@ -1,78 +1,267 @@
 package services
 
 import (
+	"backend/constants"
 	"backend/models"
 	"backend/repository"
+	"backend/utils"
+	"encoding/csv"
 	"errors"
-	"strconv"
-	"time"
+	"fmt"
+	"io"
+
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
 )
 
-func CreateCasesFromCSV(env *models.Env, records [][]string) ([]models.Case, error) {
-	if len(records) < 2 { // Check if there's at least a header and one data row
-		return nil, errors.New("CSV file is empty or missing data rows")
+var errImportRejected = errors.New("import rejected")
+
+// ImportCasesFromCSV streams a case upload, validating every row and writing
+// valid rows in batches within one transaction, so memory stays bounded by the
+// batch size. In all-or-nothing mode a single bad row rolls the whole file
+// back, otherwise the valid rows are imported. onProgress, when set, is
+// called after every batch.
+func ImportCasesFromCSV(env *models.Env, reader *csv.Reader, mode string, onProgress func(*models.ImportReport)) (*models.ImportReport, error) {
+	if mode != constants.IMPORT_MODE_ALL_OR_NOTHING && mode != constants.IMPORT_MODE_ACCEPT_VALID {
+		return nil, fmt.Errorf("invalid import mode: %s", mode)
+	}
+
+	// Short rows are reported per row instead of failing the whole file
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("CSV file is empty or missing header row")
+	}
+	parser, err := newCaseRowParser(header)
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize := viper.GetInt("CASE_IMPORT_BATCH_SIZE")
+	if batchSize <= 0 {
+		batchSize = constants.DEFAULT_CASE_IMPORT_BATCH_SIZE
+	}
+
+	// Cases uploaded by a lender's users belong to that lender and only
+	// refresh its own open cases. Users who see every case may upload
+	// without a lender, refreshing an open case of any lender.
+	userRepo := repository.NewUserRepository(env.DbConn)
+	lenderID, err := userRepo.GetUserLenderID(env.AuthDtos.User.ID)
+	if err != nil {
+		return nil, err
 	}
+	var lenderScope *string
+	switch {
+	case lenderID != "":
+		lenderScope = &lenderID
+	case !utils.HasPermission(env, "view_all_cases"):
+		return nil, ErrNoLender
+	}
+
+	report := &models.ImportReport{Mode: mode, Errors: []models.ImportRowError{}}
+	err = env.DbConn.Transaction(func(tx *gorm.DB) error {
+		txEnv := *env
+		txEnv.DbConn = tx
+
+		cases := make([]models.Case, 0, batchSize)
+		customers := make([]models.Customer, 0, batchSize)
+		flush := func() error {
+			// Once an all-or-nothing import has a bad row nothing more is written
+			writing := mode == constants.IMPORT_MODE_ACCEPT_VALID || report.Rejected == 0
+			if writing && len(cases) > 0 {
+				if err := UpsertCustomers(&txEnv, customers); err != nil {
+					return err
+				}
+				if err := upsertCases(&txEnv, lenderScope, cases, report); err != nil {
+					return err
+				}
+				report.Imported += len(cases)
+			}
+			cases = cases[:0]
+			customers = customers[:0]
+			if onProgress != nil {
+				onProgress(report)
+			}
+			return nil
+		}
 
-	// Skip header row (first row)
-	var cases []models.Case
-	for i := 1; i < len(records); i++ {
-		record := records[i]
-		if len(record) < 16 { // We expect 16 columns as per the CSV structure
-			continue
+		seenLoanIDs := map[string]int{}
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			report.TotalRows++
+			if err != nil {
+				line := report.TotalRows + 1
+				var parseErr *csv.ParseError
+				if errors.As(err, &parseErr) {
+					line = parseErr.StartLine
+				}
+				addImportErrors(report, models.ImportRowError{Row: line, Reason: err.Error()})
+				continue
+			}
+
+			row, _ := reader.FieldPos(0)
+			case_, customer, rowErrors := parser.parse(row, record)
+			if firstRow, duplicate := seenLoanIDs[case_.LoanID]; duplicate && case_.LoanID != "" {
+				rowErrors = append(rowErrors, models.ImportRowError{
+					Row:    row,
+					Field:  "loan_id",
+					Reason: fmt.Sprintf("duplicate of row %d", firstRow),
+				})
+			}
+			if len(rowErrors) > 0 {
+				addImportErrors(report, rowErrors...)
+				continue
+			}
+
+			seenLoanIDs[case_.LoanID] = row
+			if lenderID != "" {
+				case_.LenderID = &lenderID
+			}
+			cases = append(cases, *case_)
+			if customer != nil {
+				customers = append(customers, *customer)
+			}
+			if len(cases) == batchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := flush(); err != nil {
+			return err
 		}
 
-		// Parse numeric values
-		emiAmount, _ := strconv.ParseFloat(record[2], 64)
-		principalOutstanding, _ := strconv.ParseFloat(record[3], 64)
-		interestOutstanding, _ := strconv.ParseFloat(record[4], 64)
-		dpd, _ := strconv.Atoi(record[8])
-		emisPaidTillDate, _ := strconv.Atoi(record[12])
-		emisPending, _ := strconv.Atoi(record[13])
-		bounceCharges, _ := strconv.ParseFloat(record[14], 64)
-
-		// Parse dates
-		emiDate, _ := time.Parse("2006-01-02", record[6])
-		disbursalDate, _ := time.Parse("2006-01-02", record[9])
-
-		// Parse boolean
-		insuranceActive := record[10] == "true"
-
-		// Create Case struct
-		case_ := models.Case{
-			LoanID:                 record[0],
-			ExternalCustomerID:     record[1],
-			EMIAmount:              emiAmount,
-			PrincipalOutstanding:   principalOutstanding,
-			InterestOutstanding:    interestOutstanding,
-			CaseStatus:             record[5],
-			EMIDate:                emiDate,
-			DPDBucket:              record[7],
-			DPD:                    dpd,
-			DisbursalDate:          disbursalDate,
-			InsuranceActive:        insuranceActive,
-			LoanDescription:        record[11],
-			EMIsPaidTillDate:       emisPaidTillDate,
-			EMIsPending:            emisPending,
-			BounceCharges:          bounceCharges,
-			NachPresentationStatus: record[15],
-			CreatedAt:              time.Now(),
-			UpdatedAt:              time.Now(),
+		if report.TotalRows == 0 {
+			return errors.New("CSV file is empty or missing data rows")
 		}
-		cases = append(cases, case_)
+		if mode == constants.IMPORT_MODE_ALL_OR_NOTHING && report.Rejected > 0 {
+			report.Imported, report.Created, report.Updated, report.Unchanged = 0, 0, 0, 0
+			return errImportRejected
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportRejected) {
+		return nil, err
+	}
+	return report, nil
+}
+
+// addImportErrors records a rejected row, keeping at most
+// CASE_IMPORT_MAX_REPORTED_ERRORS errors in the report
+func addImportErrors(report *models.ImportReport, rowErrors ...models.ImportRowError) {
+	report.Rejected++
+	room := constants.CASE_IMPORT_MAX_REPORTED_ERRORS - len(report.Errors)
+	if len(rowErrors) > room {
+		rowErrors = rowErrors[:max(room, 0)]
+		report.ErrorsTruncated = true
+	}
+	report.Errors = append(report.Errors, rowErrors...)
+}
+
+// upsertCases creates cases for new loans and refreshes the open case of
+// loans already on file for the lender, so monthly re-uploads never
+// duplicate a loan
+func upsertCases(env *models.Env, lenderID *string, cases []models.Case, report *models.ImportReport) error {
+	loanIDs := make([]string, 0, len(cases))
+	for _, caseData := range cases {
+		loanIDs = append(loanIDs, caseData.LoanID)
 	}
 
 	repo := repository.NewCaseRepository(env.DbConn)
-	return repo.CreateCases(cases)
+	existingCases, err := repo.GetOpenCasesByLoanIDs(lenderID, loanIDs)
+	if err != nil {
+		return err
+	}
+
+	var newCases, changedCases []models.Case
+	for _, caseData := range cases {
+		existing, found := existingCases[caseData.LoanID]
+		switch {
+		case !found:
+			newCases = append(newCases, caseData)
+		case caseRefreshed(existing, caseData):
+			caseData.ID = existing.ID
+			changedCases = append(changedCases, caseData)
+		default:
+			report.Unchanged++
+		}
+	}
+
+	if len(newCases) > 0 {
+		if _, err := repo.CreateCases(newCases); err != nil {
+			return err
+		}
+	}
+	if len(changedCases) > 0 {
+		if err := repo.RefreshCases(changedCases); err != nil {
+			return err
+		}
+	}
+	report.Created = len(newCases)
+	report.Updated = len(changedCases)
+	return nil
+}
+
+// caseRefreshed reports whether an upload row changes any refreshable field
+func caseRefreshed(existing, incoming models.Case) bool {
+	return existing.EMIAmount != incoming.EMIAmount ||
+		existing.PrincipalOutstanding != incoming.PrincipalOutstanding ||
+		existing.InterestOutstanding != incoming.InterestOutstanding ||
+		!existing.EMIDate.Equal(incoming.EMIDate) ||
+		existing.DPDBucket != incoming.DPDBucket ||
+		existing.DPD != incoming.DPD ||
+		existing.InsuranceActive != incoming.InsuranceActive ||
+		existing.EMIsPaidTillDate != incoming.EMIsPaidTillDate ||
+		existing.EMIsPending != incoming.EMIsPending ||
+		existing.BounceCharges != incoming.BounceCharges ||
+		existing.NachPresentationStatus != incoming.NachPresentationStatus
 }
 
-func GetUnassignedCases(env *models.Env) ([]models.Case, error) {
+// GetUnassignedCases lists the cases not yet allocated to an agency
+func GetUnassignedCases(env *models.Env, filter models.CaseFilter) ([]models.Case, int64, error) {
 	repo := repository.NewCaseRepository(env.DbConn)
-	return repo.GetUnassignedCases()
+	filter.UnassignedToAgency = true
+	return repo.ListCases(filter)
 }
 
+// AssignCasesToAgency allocates the cases to an agency. Every case must be
+// in a status that allows allocation, otherwise nothing is allocated.
 func AssignCasesToAgency(env *models.Env, agencyID string, caseIDs []string) error {
-	repo := repository.NewCaseRepository(env.DbConn)
-	return repo.AssignCasesToAgency(agencyID, caseIDs)
+	err := env.DbConn.Transaction(func(tx *gorm.DB) error {
+		return allocateCases(tx, agencyID, caseIDs, &env.AuthDtos.User.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	AuditEntity(env, constants.AUDIT_ENTITY_CASE, "")
+	AuditChange(env, nil, map[string]interface{}{"agency_id": agencyID, "case_ids": caseIDs})
+	return nil
+}
+
+// allocateCases moves the cases to ALLOCATED_TO_AGENCY and maps them to an
+// agency inside tx
+func allocateCases(tx *gorm.DB, agencyID string, caseIDs []string, actorID *string) error {
+	repo := repository.NewCaseRepository(tx)
+	for _, caseID := range caseIDs {
+		caseData, err := repo.GetCaseForUpdate(caseID)
+		if err != nil {
+			return err
+		}
+		err = transitionCase(tx, caseData, constants.CASE_STATUS_ALLOCATED_TO_AGENCY, constants.CASE_REASON_AGENCY_ALLOCATION, "", actorID)
+		if err != nil {
+			return err
+		}
+		if err := repo.UpdateCase(caseData); err != nil {
+			return err
+		}
+	}
+	return repo.AssignCasesToAgency(agencyID, caseIDs, actorID)
 }
 
 func GetAssignedUserByCaseID(env *models.Env, caseID string) (*models.User, error) {
@@ -80,20 +269,27 @@ func GetAssignedUserByCaseID(env *models.Env, caseID string) (*models.User, erro
 	return repo.GetAssignedUserByCaseID(caseID)
 }
 
-func GetAssignedCases(env *models.Env, userID string) ([]models.Case, error) {
+// GetAssignedCases lists the cases assigned to an agent
+func GetAssignedCases(env *models.Env, userID string, filter models.CaseFilter) ([]models.Case, int64, error) {
 	repo := repository.NewCaseRepository(env.DbConn)
-	return repo.GetAssignedCases(userID)
+	filter.AssignedUserID = &userID
+	return repo.ListCases(filter)
 }
 
-func GetCaseDetails(env *models.Env, caseID string) (*models.Case, *models.User, error) {
+func GetCaseDetails(env *models.Env, caseID string) (*models.Case, *models.User, *models.Customer, error) {
 	repo := repository.NewCaseRepository(env.DbConn)
 	caseData, err := repo.GetCase(caseID)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	userData, err := repo.GetAssignedUserByCaseID(caseID)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
+	}
+	customerRepo := repository.NewCustomerRepository(env.DbConn)
+	customer, err := customerRepo.GetByExternalID(caseData.ExternalCustomerID)
+	if err != nil {
+		return nil, nil, nil, err
 	}
-	return caseData, userData, nil
+	return caseData, userData, customer, nil
 }
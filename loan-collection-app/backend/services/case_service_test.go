@@ -0,0 +1,127 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// loanCasesConnector is a database holding the open cases of several
+// lenders. It answers the open case lookup of an upload with the cases whose
+// loan ID and, when the query filters on one, lender are among its arguments,
+// and records every other statement it receives.
+type loanCasesConnector struct {
+	cases      []models.Case
+	statements []string
+}
+
+func (c *loanCasesConnector) Connect(context.Context) (driver.Conn, error) {
+	return &loanCasesConn{c}, nil
+}
+
+func (c *loanCasesConnector) Driver() driver.Driver {
+	return nil
+}
+
+type loanCasesConn struct {
+	connector *loanCasesConnector
+}
+
+func (c *loanCasesConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepared statements are not supported")
+}
+
+func (c *loanCasesConn) Close() error {
+	return nil
+}
+
+func (c *loanCasesConn) Begin() (driver.Tx, error) {
+	return c, nil
+}
+
+func (c *loanCasesConn) Commit() error {
+	return nil
+}
+
+func (c *loanCasesConn) Rollback() error {
+	return nil
+}
+
+func (c *loanCasesConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.connector.statements = append(c.connector.statements, query)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *loanCasesConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if !strings.Contains(query, "loan_id IN") {
+		c.connector.statements = append(c.connector.statements, query)
+		return &countingRows{}, nil
+	}
+
+	values := make([]driver.Value, 0, len(args))
+	for _, arg := range args {
+		values = append(values, arg.Value)
+	}
+	rows := &countingRows{columns: []string{"id", "loan_id", "lender_id", "emi_amount"}}
+	for _, caseData := range c.connector.cases {
+		if !slices.Contains(values, driver.Value(caseData.LoanID)) {
+			continue
+		}
+		if strings.Contains(query, "lender_id =") && !slices.Contains(values, driver.Value(*caseData.LenderID)) {
+			continue
+		}
+		rows.values = append(rows.values, []driver.Value{caseData.ID, caseData.LoanID, *caseData.LenderID, caseData.EMIAmount})
+	}
+	return rows, nil
+}
+
+func TestUpsertCasesKeepsLendersApart(t *testing.T) {
+	lenderA := "00000000-0000-0000-0000-00000000000a"
+	lenderB := "00000000-0000-0000-0000-00000000000b"
+
+	tests := []struct {
+		name      string
+		lenderID  *string
+		created   int
+		updated   int
+		statement string
+	}{
+		{"another lender's loan ID opens a new case", &lenderB, 1, 0, `INSERT INTO "cases"`},
+		{"the lender's own open case is refreshed", &lenderA, 0, 1, `UPDATE "cases"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connector := &loanCasesConnector{cases: []models.Case{
+				{ID: "case-a", LoanID: "L1", LenderID: &lenderA, EMIAmount: 1000},
+			}}
+			db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(connector)}), &gorm.Config{
+				Logger: logger.Discard,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			upload := models.Case{LoanID: "L1", LenderID: tt.lenderID, EMIAmount: 2000}
+			report := &models.ImportReport{}
+			if err := upsertCases(&models.Env{DbConn: db}, tt.lenderID, []models.Case{upload}, report); err != nil {
+				t.Fatal(err)
+			}
+
+			if report.Created != tt.created || report.Updated != tt.updated {
+				t.Errorf("created %d and updated %d cases, want %d and %d", report.Created, report.Updated, tt.created, tt.updated)
+			}
+			if len(connector.statements) != 1 || !strings.HasPrefix(connector.statements[0], tt.statement) {
+				t.Errorf("statements = %q, want one %s", connector.statements, tt.statement)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"backend/constants"
+	"backend/models"
+	"backend/repository"
+	"errors"
+	"fmt"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidStatusChange = errors.New("invalid status change")
+	ErrStatusNotAllowed    = errors.New("status change not allowed")
+)
+
+// CaseTransitionError is returned when the lifecycle does not allow a move
+type CaseTransitionError struct {
+	From string
+	To   string
+}
+
+func (e *CaseTransitionError) Error() string {
+	return fmt.Sprintf("case cannot move from %s to %s", e.From, e.To)
+}
+
+// CanTransitionCase reports whether the lifecycle allows moving from one status to another
+func CanTransitionCase(from, to string) bool {
+	return slices.Contains(constants.CaseStatusTransitions[from], to)
+}
+
+// transitionCase moves a case loaded inside tx to a new status and records
+// the change. The caller saves the case.
+func transitionCase(tx *gorm.DB, caseData *models.Case, toStatus, reasonCode, remarks string, actorID *string) error {
+	if !CanTransitionCase(caseData.CaseStatus, toStatus) {
+		return &CaseTransitionError{From: caseData.CaseStatus, To: toStatus}
+	}
+
+	change := &models.CaseStatusChange{
+		CaseID:     caseData.ID,
+		FromStatus: caseData.CaseStatus,
+		ToStatus:   toStatus,
+		ReasonCode: reasonCode,
+		Remarks:    remarks,
+		ActorID:    actorID,
+	}
+	caseData.CaseStatus = toStatus
+
+	statusRepo := repository.NewCaseStatusRepository(tx)
+	return statusRepo.CreateStatusChange(change)
+}
+
+// TransitionCase moves a case on behalf of the signed in user
+func TransitionCase(env *models.Env, caseID, toStatus, reasonCode, remarks string) (*models.Case, error) {
+	if _, known := constants.CaseStatusTransitions[toStatus]; !known {
+		return nil, fmt.Errorf("%w: unknown status %s", ErrInvalidStatusChange, toStatus)
+	}
+	if !slices.Contains(constants.CaseStatusReasons, reasonCode) {
+		return nil, fmt.Errorf("%w: unknown reason code %s", ErrInvalidStatusChange, reasonCode)
+	}
+	if reasonCode == constants.CASE_REASON_OTHER && remarks == "" {
+		return nil, fmt.Errorf("%w: remarks are required for reason %s", ErrInvalidStatusChange, reasonCode)
+	}
+	switch toStatus {
+	case constants.CASE_STATUS_NEW, constants.CASE_STATUS_ALLOCATED_TO_AGENCY:
+		return nil, fmt.Errorf("%w: cases move back to %s by recalling them", ErrInvalidStatusChange, toStatus)
+	case constants.CASE_STATUS_ASSIGNED_TO_AGENT:
+		return nil, fmt.Errorf("%w: cases move to %s by assigning them", ErrInvalidStatusChange, toStatus)
+	case constants.CASE_STATUS_PARTIALLY_PAID, constants.CASE_STATUS_SETTLED:
+		return nil, fmt.Errorf("%w: %s is set by posting a payment", ErrInvalidStatusChange, toStatus)
+	}
+	allowed, err := manualCaseStatusTargets(env)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(allowed, toStatus) {
+		return nil, fmt.Errorf("%w: your roles cannot move a case to %s", ErrStatusNotAllowed, toStatus)
+	}
+
+	var caseData *models.Case
+	var fromStatus string
+	err = env.DbConn.Transaction(func(tx *gorm.DB) error {
+		caseRepo := repository.NewCaseRepository(tx)
+		var err error
+		caseData, err = caseRepo.GetCaseForUpdate(caseID)
+		if err != nil {
+			return err
+		}
+		fromStatus = caseData.CaseStatus
+
+		if err := transitionCase(tx, caseData, toStatus, reasonCode, remarks, &env.AuthDtos.User.ID); err != nil {
+			return err
+		}
+		return caseRepo.UpdateCase(caseData)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	AuditChange(env,
+		map[string]interface{}{"case_status": fromStatus},
+		map[string]interface{}{"case_status": caseData.CaseStatus, "reason_code": reasonCode, "remarks": remarks})
+	return caseData, nil
+}
+
+// manualCaseStatusTargets returns the statuses the signed in user's roles may
+// set by hand
+func manualCaseStatusTargets(env *models.Env) ([]string, error) {
+	roleRepo := repository.NewRoleRepository(env.DbConn)
+	roles, err := roleRepo.GetRolesByUser(env.AuthDtos.User.ID)
+	if err != nil {
+		return nil, err
+	}
+	allowed := []string{}
+	for _, role := range roles {
+		allowed = append(allowed, constants.ManualCaseStatusTargets[role.RoleName]...)
+	}
+	return allowed, nil
+}
+
+func GetCaseStatusHistory(env *models.Env, caseID string) ([]models.CaseStatusChangeDetails, error) {
+	statusRepo := repository.NewCaseStatusRepository(env.DbConn)
+	return statusRepo.ListStatusChangesByCase(caseID)
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"backend/constants"
+	"slices"
+	"testing"
+)
+
+func TestCanTransitionCase(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{constants.CASE_STATUS_NEW, constants.CASE_STATUS_ALLOCATED_TO_AGENCY, true},
+		{constants.CASE_STATUS_NEW, constants.CASE_STATUS_ASSIGNED_TO_AGENT, false},
+		{constants.CASE_STATUS_ALLOCATED_TO_AGENCY, constants.CASE_STATUS_ASSIGNED_TO_AGENT, true},
+		{constants.CASE_STATUS_ASSIGNED_TO_AGENT, constants.CASE_STATUS_IN_PROGRESS, true},
+		{constants.CASE_STATUS_IN_PROGRESS, constants.CASE_STATUS_PTP, true},
+		{constants.CASE_STATUS_PTP, constants.CASE_STATUS_BROKEN_PTP, true},
+		{constants.CASE_STATUS_IN_PROGRESS, constants.CASE_STATUS_BROKEN_PTP, false},
+		{constants.CASE_STATUS_PARTIALLY_PAID, constants.CASE_STATUS_SETTLED, true},
+		{constants.CASE_STATUS_PARTIALLY_PAID, constants.CASE_STATUS_PARTIALLY_PAID, false},
+		{constants.CASE_STATUS_SETTLED, constants.CASE_STATUS_CLOSED, true},
+		{constants.CASE_STATUS_SETTLED, constants.CASE_STATUS_PARTIALLY_PAID, false},
+		{constants.CASE_STATUS_CLOSED, constants.CASE_STATUS_NEW, false},
+		{constants.CASE_STATUS_WITHDRAWN, constants.CASE_STATUS_IN_PROGRESS, false},
+		{"UNKNOWN", constants.CASE_STATUS_NEW, false},
+	}
+	for _, tt := range tests {
+		if got := CanTransitionCase(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransitionCase(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestCaseStatusTransitionsTargetKnownStatuses(t *testing.T) {
+	for from, targets := range constants.CaseStatusTransitions {
+		for _, to := range targets {
+			if _, known := constants.CaseStatusTransitions[to]; !known {
+				t.Errorf("%s moves to unknown status %s", from, to)
+			}
+		}
+	}
+	for _, status := range constants.ClosedCaseStatuses {
+		for _, to := range constants.CaseStatusTransitions[status] {
+			if !slices.Contains(constants.ClosedCaseStatuses, to) {
+				t.Errorf("closed status %s reopens to %s", status, to)
+			}
+		}
+	}
+}
+
+func TestManualCaseStatusTargets(t *testing.T) {
+	reserved := []string{
+		constants.CASE_STATUS_NEW,
+		constants.CASE_STATUS_ALLOCATED_TO_AGENCY,
+		constants.CASE_STATUS_ASSIGNED_TO_AGENT,
+		constants.CASE_STATUS_PARTIALLY_PAID,
+		constants.CASE_STATUS_SETTLED,
+	}
+	for role, targets := range constants.ManualCaseStatusTargets {
+		for _, status := range targets {
+			if slices.Contains(reserved, status) {
+				t.Errorf("role %s may set %s by hand", role, status)
+			}
+		}
+	}
+	for _, status := range []string{constants.CASE_STATUS_CLOSED, constants.CASE_STATUS_WITHDRAWN} {
+		for _, role := range []string{"agent", "agency_admin"} {
+			if slices.Contains(constants.ManualCaseStatusTargets[role], status) {
+				t.Errorf("role %s may close a case as %s", role, status)
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"backend/models"
+	"backend/repository"
+	"slices"
+	"time"
+)
+
+// UpsertCustomers saves customers from an upload, keeping phones and
+// addresses already on file and adding any new ones
+func UpsertCustomers(env *models.Env, customers []models.Customer) error {
+	if len(customers) == 0 {
+		return nil
+	}
+
+	// Collapse rows for the same customer, e.g. one row per loan
+	merged := map[string]*models.Customer{}
+	externalIDs := []string{}
+	for i := range customers {
+		customer := customers[i]
+		if existing, ok := merged[customer.ExternalCustomerID]; ok {
+			mergeCustomer(existing, &customer)
+			continue
+		}
+		merged[customer.ExternalCustomerID] = &customer
+		externalIDs = append(externalIDs, customer.ExternalCustomerID)
+	}
+
+	customerRepo := repository.NewCustomerRepository(env.DbConn)
+	existingCustomers, err := customerRepo.ListByExternalIDs(externalIDs)
+	if err != nil {
+		return err
+	}
+
+	result := []models.Customer{}
+	for i := range existingCustomers {
+		existing := existingCustomers[i]
+		mergeCustomer(&existing, merged[existing.ExternalCustomerID])
+		result = append(result, existing)
+		delete(merged, existing.ExternalCustomerID)
+	}
+	for _, externalID := range externalIDs {
+		if customer, ok := merged[externalID]; ok {
+			result = append(result, *customer)
+		}
+	}
+
+	now := time.Now()
+	for i := range result {
+		result[i].UpdatedAt = now
+	}
+	return customerRepo.UpsertCustomers(result)
+}
+
+func GetCustomer(env *models.Env, externalCustomerID string) (*models.Customer, error) {
+	customerRepo := repository.NewCustomerRepository(env.DbConn)
+	return customerRepo.GetByExternalID(externalCustomerID)
+}
+
+// mergeCustomer copies non empty details from incoming onto target
+func mergeCustomer(target, incoming *models.Customer) {
+	if incoming.Name != "" {
+		target.Name = incoming.Name
+	}
+	if incoming.Email != nil && *incoming.Email != "" {
+		target.Email = incoming.Email
+	}
+	if incoming.Pincode != "" {
+		target.Pincode = incoming.Pincode
+	}
+	for _, phone := range incoming.Phones {
+		if !slices.Contains(target.Phones, phone) {
+			target.Phones = append(target.Phones, phone)
+		}
+	}
+	for _, address := range incoming.Addresses {
+		if !slices.Contains(target.Addresses, address) {
+			target.Addresses = append(target.Addresses, address)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"backend/constants"
+	"backend/models"
+	"backend/repository"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+func maxDPD(days int) *int {
+	return &days
+}
+
+// defaultDPDBuckets is used when DPD_BUCKETS is not configured
+var defaultDPDBuckets = []models.DPDBucket{
+	{Name: "CURRENT", MaxDPD: maxDPD(0)},
+	{Name: "DPD30", MaxDPD: maxDPD(30)},
+	{Name: "DPD60", MaxDPD: maxDPD(60)},
+	{Name: "DPD90", MaxDPD: maxDPD(90)},
+	{Name: "DPD180", MaxDPD: maxDPD(180)},
+	{Name: "DPD180+"},
+}
+
+// dpdBuckets reads the bucket table from DPD_BUCKETS. Buckets must be in
+// ascending order and only the last one may be open ended.
+func dpdBuckets() ([]models.DPDBucket, error) {
+	if !viper.IsSet("DPD_BUCKETS") {
+		return defaultDPDBuckets, nil
+	}
+
+	var buckets []models.DPDBucket
+	if err := viper.UnmarshalKey("DPD_BUCKETS", &buckets); err != nil {
+		return nil, err
+	}
+	if len(buckets) == 0 {
+		return nil, errors.New("DPD_BUCKETS is empty")
+	}
+	previous := -1
+	for i, bucket := range buckets {
+		if bucket.Name == "" {
+			return nil, fmt.Errorf("DPD bucket %d has no name", i+1)
+		}
+		if bucket.MaxDPD == nil {
+			if i != len(buckets)-1 {
+				return nil, fmt.Errorf("DPD bucket %s has no max_dpd but is not the last bucket", bucket.Name)
+			}
+			continue
+		}
+		if *bucket.MaxDPD <= previous {
+			return nil, fmt.Errorf("DPD bucket %s is out of order", bucket.Name)
+		}
+		previous = *bucket.MaxDPD
+	}
+	return buckets, nil
+}
+
+// dpdBucketFor returns the name of the bucket holding dpd, or the last
+// bucket when the table ends below it
+func dpdBucketFor(buckets []models.DPDBucket, dpd int) string {
+	for _, bucket := range buckets {
+		if bucket.MaxDPD == nil || dpd <= *bucket.MaxDPD {
+			return bucket.Name
+		}
+	}
+	return buckets[len(buckets)-1].Name
+}
+
+// computeDPD counts the days since the oldest unpaid EMI fell due. The EMI
+// date moves a month forward for every whole EMI paid since it. Cases with
+// nothing outstanding are current.
+func computeDPD(caseData models.Case, paidSinceEMIDate float64, asOf time.Time) int {
+	if caseData.PrincipalOutstanding+caseData.InterestOutstanding+caseData.BounceCharges <= 0 {
+		return 0
+	}
+	if caseData.EMIDate.IsZero() {
+		return caseData.DPD
+	}
+
+	overdueSince := dateOnly(caseData.EMIDate)
+	if caseData.EMIAmount > 0 {
+		overdueSince = overdueSince.AddDate(0, int(paidSinceEMIDate/caseData.EMIAmount), 0)
+	}
+	days := int(dateOnly(asOf).Sub(overdueSince).Hours() / 24)
+	return max(days, 0)
+}
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// RecomputeDPD recalculates DPD and bucket of every open case as of the
+// given day and stores that day's snapshot. Running it twice for the same
+// day replaces the snapshot.
+func RecomputeDPD(env *models.Env, asOf time.Time) (*models.DPDRecomputeResult, error) {
+	buckets, err := dpdBuckets()
+	if err != nil {
+		return nil, err
+	}
+	batchSize := viper.GetInt("DPD_RECOMPUTE_BATCH_SIZE")
+	if batchSize <= 0 {
+		batchSize = constants.DEFAULT_DPD_RECOMPUTE_BATCH_SIZE
+	}
+
+	asOf = dateOnly(asOf)
+	result := &models.DPDRecomputeResult{AsOf: asOf}
+
+	caseRepo := repository.NewCaseRepository(env.DbConn)
+	err = caseRepo.FindOpenCasesInBatches(batchSize, func(cases []models.Case) error {
+		caseIDs := make([]string, 0, len(cases))
+		for _, caseData := range cases {
+			caseIDs = append(caseIDs, caseData.ID)
+		}
+
+		return env.DbConn.Transaction(func(tx *gorm.DB) error {
+			txCaseRepo := repository.NewCaseRepository(tx)
+			paid, err := repository.NewPaymentRepository(tx).SumPaymentsSinceEMIDate(caseIDs, asOf)
+			if err != nil {
+				return err
+			}
+			agencyIDs, err := txCaseRepo.GetAgencyIDsByCases(caseIDs)
+			if err != nil {
+				return err
+			}
+
+			snapshots := make([]models.DPDSnapshot, 0, len(cases))
+			for _, caseData := range cases {
+				dpd := computeDPD(caseData, paid[caseData.ID], asOf)
+				bucket := dpdBucketFor(buckets, dpd)
+				if dpd != caseData.DPD || bucket != caseData.DPDBucket {
+					if err := txCaseRepo.UpdateCaseDPD(caseData.ID, dpd, bucket); err != nil {
+						return err
+					}
+					result.Updated++
+				}
+
+				snapshot := models.DPDSnapshot{
+					SnapshotDate:         asOf,
+					CaseID:               caseData.ID,
+					LenderID:             caseData.LenderID,
+					CaseStatus:           caseData.CaseStatus,
+					DPD:                  dpd,
+					DPDBucket:            bucket,
+					PrincipalOutstanding: caseData.PrincipalOutstanding,
+					InterestOutstanding:  caseData.InterestOutstanding,
+				}
+				if agencyID, ok := agencyIDs[caseData.ID]; ok {
+					snapshot.AgencyID = &agencyID
+				}
+				snapshots = append(snapshots, snapshot)
+			}
+
+			if err := repository.NewDPDRepository(tx).UpsertSnapshots(snapshots); err != nil {
+				return err
+			}
+			result.Processed += len(cases)
+			result.Snapshotted += len(snapshots)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// StartDPDScheduler runs RecomputeDPD once a day at DPD_RECOMPUTE_TIME, local
+// time, until the process exits. With several instances running, a Redis
+// lock lets only one of them run each day.
+func StartDPDScheduler(env *models.Env) error {
+	runAt := viper.GetString("DPD_RECOMPUTE_TIME")
+	if runAt == "" {
+		runAt = constants.DEFAULT_DPD_RECOMPUTE_TIME
+	}
+	clock, err := time.Parse("15:04", runAt)
+	if err != nil {
+		return fmt.Errorf("invalid DPD_RECOMPUTE_TIME %q: %w", runAt, err)
+	}
+
+	go func() {
+		for {
+			now := time.Now()
+			next := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+			if !next.After(now) {
+				next = next.AddDate(0, 0, 1)
+			}
+			time.Sleep(time.Until(next))
+			runScheduledDPDRecompute(env, next)
+		}
+	}()
+	return nil
+}
+
+func runScheduledDPDRecompute(env *models.Env, day time.Time) {
+	if env.RedisClient != nil {
+		lockRepo := repository.NewJobLockRepository(env.RedisClient)
+		acquired, err := lockRepo.AcquireJobLock("recompute_dpd:"+day.Format("2006-01-02"), 23*time.Hour)
+		if err != nil {
+			env.Logger.Error(err.Error())
+			return
+		}
+		if !acquired {
+			return
+		}
+	}
+
+	result, err := RecomputeDPD(env, day)
+	if err != nil {
+		env.Logger.Error(err.Error())
+		return
+	}
+	env.Logger.Info(fmt.Sprintf("DPD recomputed as of %s: %d cases processed, %d updated",
+		result.AsOf.Format("2006-01-02"), result.Processed, result.Updated))
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"backend/models"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestComputeDPD(t *testing.T) {
+	emiDate := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	newCase := func() models.Case {
+		return models.Case{EMIDate: emiDate, EMIAmount: 1000, PrincipalOutstanding: 10000, DPD: 7}
+	}
+	tests := []struct {
+		name   string
+		change func(*models.Case)
+		paid   float64
+		asOf   time.Time
+		want   int
+	}{
+		{"due today", nil, 0, emiDate, 0},
+		{"before the due date", nil, 0, emiDate.AddDate(0, 0, -3), 0},
+		{"days since the due date", nil, 0, time.Date(2026, 2, 4, 0, 0, 0, 0, time.UTC), 30},
+		{"time of day is ignored", nil, 0, time.Date(2026, 1, 6, 23, 59, 0, 0, time.UTC), 1},
+		{"whole EMIs paid move the due date", nil, 2500, time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), 5},
+		{"paid past the run date", nil, 3000, time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), 0},
+		{"nothing outstanding", func(c *models.Case) { c.PrincipalOutstanding = 0 }, 0, emiDate.AddDate(0, 0, 90), 0},
+		{"bounce charges keep a case overdue", func(c *models.Case) { c.PrincipalOutstanding, c.BounceCharges = 0, 500 }, 0, emiDate.AddDate(0, 0, 10), 10},
+		{"no EMI amount", func(c *models.Case) { c.EMIAmount = 0 }, 5000, emiDate.AddDate(0, 0, 10), 10},
+		{"zero EMI date keeps the uploaded DPD", func(c *models.Case) { c.EMIDate = time.Time{} }, 0, emiDate.AddDate(0, 0, 90), 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caseData := newCase()
+			if tt.change != nil {
+				tt.change(&caseData)
+			}
+			if got := computeDPD(caseData, tt.paid, tt.asOf); got != tt.want {
+				t.Errorf("computeDPD() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDPDBucketFor(t *testing.T) {
+	tests := []struct {
+		dpd  int
+		want string
+	}{
+		{0, "CURRENT"},
+		{1, "DPD30"},
+		{30, "DPD30"},
+		{31, "DPD60"},
+		{180, "DPD180"},
+		{181, "DPD180+"},
+		{5000, "DPD180+"},
+	}
+	for _, tt := range tests {
+		if got := dpdBucketFor(defaultDPDBuckets, tt.dpd); got != tt.want {
+			t.Errorf("dpdBucketFor(%d) = %s, want %s", tt.dpd, got, tt.want)
+		}
+	}
+
+	closed := []models.DPDBucket{{Name: "CURRENT", MaxDPD: maxDPD(0)}, {Name: "DPD90", MaxDPD: maxDPD(90)}}
+	if got := dpdBucketFor(closed, 91); got != "DPD90" {
+		t.Errorf("dpdBucketFor() past the last bucket = %s, want DPD90", got)
+	}
+}
+
+func TestDPDBuckets(t *testing.T) {
+	tests := []struct {
+		name    string
+		buckets any
+		wantErr bool
+	}{
+		{"valid table", []map[string]any{{"name": "CURRENT", "max_dpd": 0}, {"name": "DPD30", "max_dpd": 30}, {"name": "DPD30+"}}, false},
+		{"last bucket may be closed", []map[string]any{{"name": "CURRENT", "max_dpd": 0}, {"name": "DPD30", "max_dpd": 30}}, false},
+		{"empty table", []map[string]any{}, true},
+		{"bucket without a name", []map[string]any{{"max_dpd": 0}}, true},
+		{"open bucket before the last", []map[string]any{{"name": "CURRENT"}, {"name": "DPD30", "max_dpd": 30}}, true},
+		{"out of order", []map[string]any{{"name": "DPD60", "max_dpd": 60}, {"name": "DPD30", "max_dpd": 30}}, true},
+		{"repeated edge", []map[string]any{{"name": "DPD30", "max_dpd": 30}, {"name": "ALSO30", "max_dpd": 30}}, true},
+		{"not a table", "DPD30", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(viper.Reset)
+			viper.Set("DPD_BUCKETS", tt.buckets)
+			buckets, err := dpdBuckets()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("dpdBuckets() = %v, %v, wantErr %v", buckets, err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("default table", func(t *testing.T) {
+		viper.Reset()
+		buckets, err := dpdBuckets()
+		if err != nil || len(buckets) != len(defaultDPDBuckets) {
+			t.Errorf("dpdBuckets() = %v, %v, want the default table", buckets, err)
+		}
+	})
+}
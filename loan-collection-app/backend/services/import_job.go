@@ -0,0 +1,148 @@
+package services
+
+import (
+	"backend/constants"
+	"backend/models"
+	"backend/repository"
+	"backend/utils"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"gorm.io/datatypes"
+)
+
+// StartCaseImportJob imports a case upload saved at filePath in the
+// background and returns the job to poll for progress. The file is removed
+// once the import finishes.
+func StartCaseImportJob(env *models.Env, filePath, fileName string, fileSize int64, mode string) (*models.ImportJob, error) {
+	job := &models.ImportJob{
+		FileName:  fileName,
+		FileSize:  fileSize,
+		Mode:      mode,
+		Status:    constants.IMPORT_JOB_PENDING,
+		CreatedBy: env.AuthDtos.User.ID,
+		Report:    datatypes.NewJSONType(models.ImportReport{Mode: mode, Errors: []models.ImportRowError{}}),
+	}
+	jobRepo := repository.NewImportJobRepository(env.DbConn)
+	if err := jobRepo.CreateImportJob(job); err != nil {
+		return nil, err
+	}
+
+	// The request env is torn down once the handler returns, so the job
+	// keeps its own copy without the request context
+	jobEnv := *env
+	jobEnv.Ctx = nil
+	go runCaseImportJob(&jobEnv, job, filePath)
+
+	return job, nil
+}
+
+// GetImportJob returns an import job, or nil when it does not exist or was
+// started by someone else. Users with view_all_cases see every job.
+func GetImportJob(env *models.Env, jobID string) (*models.ImportJob, error) {
+	if _, err := uuid.Parse(jobID); err != nil {
+		return nil, nil
+	}
+	jobRepo := repository.NewImportJobRepository(env.DbConn)
+	job, err := jobRepo.GetImportJob(jobID)
+	if err != nil || job == nil {
+		return nil, err
+	}
+	if job.CreatedBy != env.AuthDtos.User.ID && !utils.HasPermission(env, "view_all_cases") {
+		return nil, nil
+	}
+	return job, nil
+}
+
+// FailStaleImportJobs fails the jobs left pending or running by a server
+// that stopped mid import. Running jobs save their progress after every
+// batch, so only jobs quiet for IMPORT_JOB_STALE_MINUTES are touched and
+// imports still running on other instances are left alone.
+func FailStaleImportJobs(env *models.Env) (int64, error) {
+	staleMinutes := viper.GetInt("IMPORT_JOB_STALE_MINUTES")
+	if staleMinutes <= 0 {
+		staleMinutes = constants.DEFAULT_IMPORT_JOB_STALE_MINUTES
+	}
+	jobRepo := repository.NewImportJobRepository(env.DbConn)
+	return jobRepo.FailImportJobs(
+		[]string{constants.IMPORT_JOB_PENDING, constants.IMPORT_JOB_RUNNING},
+		time.Now().Add(-time.Duration(staleMinutes)*time.Minute),
+		constants.IMPORT_JOB_INTERRUPTED_ERROR,
+	)
+}
+
+func runCaseImportJob(env *models.Env, job *models.ImportJob, filePath string) {
+	defer os.Remove(filePath)
+	jobRepo := repository.NewImportJobRepository(env.DbConn)
+
+	fail := func(err error) {
+		env.Logger.Error(err.Error())
+		now := time.Now()
+		job.Status = constants.IMPORT_JOB_FAILED
+		job.Error = err.Error()
+		job.FinishedAt = &now
+		if err := jobRepo.UpdateImportJob(job); err != nil {
+			env.Logger.Error(err.Error())
+		}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			fail(fmt.Errorf("import panicked: %v", r))
+		}
+	}()
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		fail(err)
+		return
+	}
+	defer file.Close()
+
+	now := time.Now()
+	job.Status = constants.IMPORT_JOB_RUNNING
+	job.StartedAt = &now
+	if err := jobRepo.UpdateImportJob(job); err != nil {
+		fail(err)
+		return
+	}
+
+	counter := &countingReader{reader: file}
+	report, err := ImportCasesFromCSV(env, csv.NewReader(counter), job.Mode, func(report *models.ImportReport) {
+		job.BytesRead = counter.count.Load()
+		job.Report = datatypes.NewJSONType(*report)
+		if err := jobRepo.UpdateImportJob(job); err != nil {
+			env.Logger.Error(err.Error())
+		}
+	})
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	finishedAt := time.Now()
+	job.Status = constants.IMPORT_JOB_COMPLETED
+	job.BytesRead = job.FileSize
+	job.Report = datatypes.NewJSONType(*report)
+	job.FinishedAt = &finishedAt
+	if err := jobRepo.UpdateImportJob(job); err != nil {
+		env.Logger.Error(err.Error())
+	}
+}
+
+// countingReader tracks how much of the upload has been consumed
+type countingReader struct {
+	reader io.Reader
+	count  atomic.Int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"backend/models"
+	"backend/repository"
+)
+
+func ListAllLenders(env *models.Env) ([]models.Lender, error) {
+	repo := repository.NewLenderRepository(env.DbConn)
+	return repo.ListAllLenders()
+}
+
+func CreateLender(env *models.Env, lender *models.Lender) error {
+	repo := repository.NewLenderRepository(env.DbConn)
+	return repo.CreateLender(lender)
+}
+
+func AssignUserToLender(env *models.Env, mapping *models.LenderUserMap) error {
+	repo := repository.NewLenderRepository(env.DbConn)
+	return repo.AssignUserToLender(mapping)
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// countingConnector is a database that answers every select with a fixed
+// number of rows and counts the statements it receives, so listings can be
+// checked for per-row queries without a PostgreSQL server
+type countingConnector struct {
+	rows    int
+	queries atomic.Int64
+}
+
+func (c *countingConnector) Connect(context.Context) (driver.Conn, error) {
+	return &countingConn{c}, nil
+}
+
+func (c *countingConnector) Driver() driver.Driver {
+	return nil
+}
+
+type countingConn struct {
+	connector *countingConnector
+}
+
+func (c *countingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepared statements are not supported")
+}
+
+func (c *countingConn) Close() error {
+	return nil
+}
+
+func (c *countingConn) Begin() (driver.Tx, error) {
+	return c, nil
+}
+
+func (c *countingConn) Commit() error {
+	return nil
+}
+
+func (c *countingConn) Rollback() error {
+	return nil
+}
+
+func (c *countingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.connector.queries.Add(1)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *countingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.connector.queries.Add(1)
+	if strings.HasPrefix(strings.ToLower(query), "select count(") {
+		return &countingRows{columns: []string{"count"}, values: [][]driver.Value{{int64(c.connector.rows)}}}, nil
+	}
+
+	columns := []string{"id", "agency_id", "assignee_id", "role_names"}
+	values := make([][]driver.Value, c.connector.rows)
+	for i := range values {
+		id := fmt.Sprintf("00000000-0000-0000-0000-%012d", i)
+		values[i] = []driver.Value{id, id, id, []byte(`["agent"]`)}
+	}
+	return &countingRows{columns: columns, values: values}, nil
+}
+
+type countingRows struct {
+	columns []string
+	values  [][]driver.Value
+	next    int
+}
+
+func (r *countingRows) Columns() []string {
+	return r.columns
+}
+
+func (r *countingRows) Close() error {
+	return nil
+}
+
+func (r *countingRows) Next(dest []driver.Value) error {
+	if r.next == len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.next])
+	r.next++
+	return nil
+}
+
+func newCountingEnv(tb testing.TB, rows int) (*models.Env, *countingConnector) {
+	connector := &countingConnector{rows: rows}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(connector)}), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return &models.Env{DbConn: db}, connector
+}
+
+// listingQueries are the listings that must cost the same number of queries
+// whatever the size of the page
+var listingQueries = []struct {
+	name    string
+	queries int64
+	list    func(env *models.Env) error
+}{
+	{"agency cases", 3, func(env *models.Env) error {
+		_, _, err := ListCases(env, "user", models.CaseFilter{PageSize: 1000})
+		return err
+	}},
+	{"agent cases", 2, func(env *models.Env) error {
+		_, _, err := GetAssignedCases(env, "user", models.CaseFilter{PageSize: 1000})
+		return err
+	}},
+	{"users", 1, func(env *models.Env) error {
+		_, err := ListAllUsers(env)
+		return err
+	}},
+}
+
+func TestListingQueriesDoNotGrowWithRows(t *testing.T) {
+	for _, listing := range listingQueries {
+		for _, rows := range []int{1, 100, 1000} {
+			env, connector := newCountingEnv(t, rows)
+			if err := listing.list(env); err != nil {
+				t.Fatalf("%s with %d rows: %v", listing.name, rows, err)
+			}
+			if got := connector.queries.Load(); got != listing.queries {
+				t.Errorf("%s with %d rows ran %d queries, want %d", listing.name, rows, got, listing.queries)
+			}
+		}
+	}
+}
+
+func BenchmarkListingQueries(b *testing.B) {
+	for _, listing := range listingQueries {
+		for _, rows := range []int{10, 1000} {
+			b.Run(fmt.Sprintf("%s/%d rows", listing.name, rows), func(b *testing.B) {
+				env, connector := newCountingEnv(b, rows)
+				for i := 0; i < b.N; i++ {
+					if err := listing.list(env); err != nil {
+						b.Fatal(err)
+					}
+				}
+				queries := float64(connector.queries.Load()) / float64(b.N)
+				b.ReportMetric(queries, "queries/op")
+				if queries != float64(listing.queries) {
+					b.Fatalf("%s ran %.1f queries per listing, want %d", listing.name, queries, listing.queries)
+				}
+			})
+		}
+	}
+}
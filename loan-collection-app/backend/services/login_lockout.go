@@ -0,0 +1,157 @@
+package services
+
+import (
+	"backend/constants"
+	"backend/models"
+	"backend/repository"
+	"errors"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+var ErrUserNotFound = errors.New("user not found")
+
+// LoginLockedError is returned while a username or IP is locked out
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return "too many failed login attempts, try again later"
+}
+
+type loginSubject struct {
+	key         string
+	maxAttempts int
+	eventType   string
+}
+
+func loginSubjects(username, ip string) []loginSubject {
+	return []loginSubject{
+		{
+			key:         "user:" + username,
+			maxAttempts: viperIntOrDefault("LOGIN_MAX_ATTEMPTS_PER_USER", constants.DEFAULT_LOGIN_MAX_ATTEMPTS_PER_USER),
+			eventType:   constants.AUTH_EVENT_ACCOUNT_LOCKED,
+		},
+		{
+			key:         "ip:" + ip,
+			maxAttempts: viperIntOrDefault("LOGIN_MAX_ATTEMPTS_PER_IP", constants.DEFAULT_LOGIN_MAX_ATTEMPTS_PER_IP),
+			eventType:   constants.AUTH_EVENT_IP_LOCKED,
+		},
+	}
+}
+
+func viperIntOrDefault(key string, fallback int) int {
+	if value := viper.GetInt(key); value > 0 {
+		return value
+	}
+	return fallback
+}
+
+// lockoutDuration doubles the base lockout for every failure past the limit,
+// up to the configured maximum
+func lockoutDuration(attempts int64, maxAttempts int) time.Duration {
+	base := time.Duration(viperIntOrDefault("LOGIN_LOCKOUT_BASE_SECONDS", constants.DEFAULT_LOGIN_LOCKOUT_BASE_SECONDS)) * time.Second
+	maximum := time.Duration(viperIntOrDefault("LOGIN_LOCKOUT_MAX_SECONDS", constants.DEFAULT_LOGIN_LOCKOUT_MAX_SECONDS)) * time.Second
+
+	duration := base
+	for i := int64(maxAttempts); i < attempts && duration < maximum; i++ {
+		duration *= 2
+	}
+	return min(duration, maximum)
+}
+
+// checkLoginLock fails with a LoginLockedError when the username or the IP is locked
+func checkLoginLock(env *models.Env, username, ip string) error {
+	attemptRepo := repository.NewLoginAttemptRepository(env.RedisClient)
+
+	var retryAfter time.Duration
+	for _, subject := range loginSubjects(username, ip) {
+		remaining, err := attemptRepo.GetLockRemaining(subject.key)
+		if err != nil {
+			return err
+		}
+		retryAfter = max(retryAfter, remaining)
+	}
+	if retryAfter > 0 {
+		return &LoginLockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed login against the username and the IP
+// and locks whichever reached its limit
+func recordLoginFailure(env *models.Env, username, ip string) error {
+	attemptRepo := repository.NewLoginAttemptRepository(env.RedisClient)
+	window := time.Duration(viperIntOrDefault("LOGIN_FAILURE_WINDOW_MINUTES", constants.DEFAULT_LOGIN_FAILURE_WINDOW_MINUTES)) * time.Minute
+
+	for _, subject := range loginSubjects(username, ip) {
+		attempts, err := attemptRepo.IncrementFailures(subject.key, window)
+		if err != nil {
+			return err
+		}
+		if attempts < int64(subject.maxAttempts) {
+			continue
+		}
+
+		duration := lockoutDuration(attempts, subject.maxAttempts)
+		if err := attemptRepo.Lock(subject.key, duration); err != nil {
+			return err
+		}
+
+		lockedUntil := time.Now().Add(duration)
+		event := &models.AuthEvent{
+			EventType:   subject.eventType,
+			Username:    username,
+			IPAddress:   ip,
+			Attempts:    int(attempts),
+			LockedUntil: &lockedUntil,
+		}
+		userRepo := repository.NewUserRepository(env.DbConn)
+		if user, _ := userRepo.FindByUsername(username); user != nil {
+			event.UserID = &user.ID
+		}
+		if err := repository.NewAuthEventRepository(env.DbConn).CreateAuthEvent(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordLoginSuccess clears the failures of the username. The IP count is
+// kept, so one valid account cannot be used to reset guessing from an IP.
+func recordLoginSuccess(env *models.Env, username string) error {
+	attemptRepo := repository.NewLoginAttemptRepository(env.RedisClient)
+	return attemptRepo.ResetFailures("user:" + username)
+}
+
+// UnlockUser lifts the lockout of a user, and of an IP when given, on behalf of an admin
+func UnlockUser(env *models.Env, actorID, userID, ip string) error {
+	userRepo := repository.NewUserRepository(env.DbConn)
+	user, err := userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	attemptRepo := repository.NewLoginAttemptRepository(env.RedisClient)
+	if err := attemptRepo.Unlock("user:" + user.Username); err != nil {
+		return err
+	}
+	if ip != "" {
+		if err := attemptRepo.Unlock("ip:" + ip); err != nil {
+			return err
+		}
+	}
+
+	return repository.NewAuthEventRepository(env.DbConn).CreateAuthEvent(&models.AuthEvent{
+		EventType: constants.AUTH_EVENT_ACCOUNT_UNLOCKED,
+		Username:  user.Username,
+		UserID:    &user.ID,
+		IPAddress: ip,
+		ActorID:   &actorID,
+	})
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"backend/constants"
+	"backend/models"
+	"backend/payments"
+	"backend/repository"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+func CreatePaymentLink(env *models.Env, caseID, amountType string, customAmount float64) (*models.PaymentLink, error) {
+	caseRepo := repository.NewCaseRepository(env.DbConn)
+	caseData, err := caseRepo.GetCase(caseID)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := paymentAmount(caseData, amountType, customAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := payments.NewProvider(env.HttpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	expiryHours := viper.GetInt("PAYMENT_LINK_EXPIRY_HOURS")
+	if expiryHours <= 0 {
+		expiryHours = constants.DEFAULT_PAYMENT_LINK_EXPIRY_HOURS
+	}
+
+	paymentLink := &models.PaymentLink{
+		ID:         uuid.NewString(),
+		CaseID:     caseData.ID,
+		CreatedBy:  env.AuthDtos.User.ID,
+		AmountType: amountType,
+		Amount:     amount,
+		Provider:   provider.Name(),
+		Status:     constants.PAYMENT_LINK_PENDING,
+		ExpiresAt:  time.Now().Add(time.Duration(expiryHours) * time.Hour),
+	}
+
+	linkRequest := payments.LinkRequest{
+		ReferenceID: paymentLink.ID,
+		Amount:      amount,
+		Currency:    "INR",
+		Description: fmt.Sprintf("Repayment for loan %s", caseData.LoanID),
+		ExpiresAt:   paymentLink.ExpiresAt,
+	}
+	// The provider prefills and notifies the customer when it knows them
+	customerRepo := repository.NewCustomerRepository(env.DbConn)
+	customer, err := customerRepo.GetByExternalID(caseData.ExternalCustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer != nil {
+		linkRequest.CustomerName = customer.Name
+		if len(customer.Phones) > 0 {
+			linkRequest.CustomerPhone = customer.Phones[0]
+		}
+		if customer.Email != nil {
+			linkRequest.CustomerEmail = *customer.Email
+		}
+	}
+
+	// Record the link before the provider creates it, so a link the
+	// customer can pay is never missing here. Its ID is the reference the
+	// provider sends back with every webhook.
+	paymentRepo := repository.NewPaymentRepository(env.DbConn)
+	if err := paymentRepo.CreatePaymentLink(paymentLink); err != nil {
+		return nil, err
+	}
+
+	link, err := provider.CreateLink(linkRequest)
+	if err != nil {
+		if statusErr := paymentRepo.UpdatePaymentLinkStatus(paymentLink.ID, constants.PAYMENT_LINK_FAILED); statusErr != nil {
+			env.Logger.Error(fmt.Sprintf("failed to mark payment link %s as failed: %v", paymentLink.ID, statusErr))
+		}
+		return nil, err
+	}
+	paymentLink.ProviderReference = link.ProviderReference
+	paymentLink.URL = link.URL
+	paymentLink.Status = constants.PAYMENT_LINK_CREATED
+
+	if err := paymentRepo.UpdateCreatedPaymentLink(paymentLink); err != nil {
+		return nil, err
+	}
+	return paymentLink, nil
+}
+
+func ListPaymentLinks(env *models.Env, caseID string) ([]models.PaymentLink, error) {
+	paymentRepo := repository.NewPaymentRepository(env.DbConn)
+	return paymentRepo.ListPaymentLinksByCase(caseID)
+}
+
+// paymentAmount resolves the amount to collect, bounded by the total amount
+// the customer owes on the case
+func paymentAmount(caseData *models.Case, amountType string, customAmount float64) (float64, error) {
+	maxAmount := caseData.PrincipalOutstanding + caseData.InterestOutstanding + caseData.BounceCharges
+
+	var amount float64
+	switch amountType {
+	case constants.PAYMENT_AMOUNT_EMI:
+		amount = caseData.EMIAmount
+	case constants.PAYMENT_AMOUNT_OVERDUE:
+		// Every started 30 days past due is one missed EMI
+		overdueEMIs := math.Ceil(float64(caseData.DPD) / 30)
+		amount = caseData.EMIAmount*overdueEMIs + caseData.BounceCharges
+	case constants.PAYMENT_AMOUNT_CUSTOM:
+		if customAmount > maxAmount {
+			return 0, fmt.Errorf("amount cannot exceed total outstanding of %.2f", maxAmount)
+		}
+		amount = customAmount
+	default:
+		return 0, fmt.Errorf("invalid amount type: %s", amountType)
+	}
+
+	amount = math.Min(amount, maxAmount)
+	if amount <= 0 {
+		return 0, errors.New("nothing to collect on this case")
+	}
+	return math.Round(amount*100) / 100, nil
+}
+
+// HandlePaymentWebhook applies a verified provider event. Events are
+// idempotent on their ID so provider retries never post a payment twice.
+// Ignored events and events for links we did not create are acknowledged
+// without doing anything, so the provider stops retrying them.
+func HandlePaymentWebhook(env *models.Env, provider string, event *payments.WebhookEvent) error {
+	if event.Type == payments.WEBHOOK_EVENT_IGNORED {
+		return nil
+	}
+
+	return env.DbConn.Transaction(func(tx *gorm.DB) error {
+		paymentRepo := repository.NewPaymentRepository(tx)
+
+		var link *models.PaymentLink
+		var err error
+		if _, parseErr := uuid.Parse(event.ReferenceID); parseErr == nil {
+			link, err = paymentRepo.GetPaymentLink(event.ReferenceID)
+		} else {
+			link, err = paymentRepo.GetPaymentLinkByProviderReference(provider, event.ProviderReference)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			env.Logger.Warn(fmt.Sprintf("%s webhook %s is for an unknown payment link", provider, event.EventID))
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch event.Type {
+		case payments.WEBHOOK_EVENT_EXPIRED:
+			return paymentRepo.UpdatePaymentLinkStatus(link.ID, constants.PAYMENT_LINK_EXPIRED)
+		case payments.WEBHOOK_EVENT_CANCELLED:
+			return paymentRepo.UpdatePaymentLinkStatus(link.ID, constants.PAYMENT_LINK_CANCELLED)
+		case payments.WEBHOOK_EVENT_PAID, payments.WEBHOOK_EVENT_PARTIALLY_PAID:
+		default:
+			return nil
+		}
+
+		// Lock the case first so concurrent retries of the same event serialise
+		caseRepo := repository.NewCaseRepository(tx)
+		caseData, err := caseRepo.GetCaseForUpdate(link.CaseID)
+		if err != nil {
+			return err
+		}
+
+		exists, err := paymentRepo.PaymentEventExists(event.EventID)
+		if err != nil || exists {
+			return err
+		}
+
+		amount := event.Amount
+		if amount <= 0 {
+			amount = link.Amount
+		}
+		payment := &models.Payment{
+			CaseID:           caseData.ID,
+			PaymentLinkID:    &link.ID,
+			Source:           constants.PAYMENT_SOURCE_PAYMENT_LINK,
+			EventID:          &event.EventID,
+			Mode:             event.Mode,
+			PaymentReference: event.PaymentReference,
+			Amount:           amount,
+			PaidAt:           event.PaidAt,
+		}
+		if err := postPayment(tx, caseData, payment); err != nil {
+			return err
+		}
+		// A partly paid link stays open for the rest of the amount
+		if event.Type == payments.WEBHOOK_EVENT_PARTIALLY_PAID {
+			return nil
+		}
+		return paymentRepo.UpdatePaymentLinkStatus(link.ID, constants.PAYMENT_LINK_PAID)
+	})
+}
+
+// postPayment records a payment and applies it to the case it belongs to.
+// It must run inside a transaction holding the case row lock.
+func postPayment(tx *gorm.DB, caseData *models.Case, payment *models.Payment) error {
+	paymentRepo := repository.NewPaymentRepository(tx)
+	if err := paymentRepo.CreatePayment(payment); err != nil {
+		return err
+	}
+
+	applyPaymentToCase(caseData, payment.Amount)
+
+	status, reason := constants.CASE_STATUS_PARTIALLY_PAID, constants.CASE_REASON_PAYMENT_RECEIVED
+	if caseData.BounceCharges+caseData.InterestOutstanding+caseData.PrincipalOutstanding <= 0 {
+		status, reason = constants.CASE_STATUS_SETTLED, constants.CASE_REASON_FULLY_PAID
+		caseData.EMIsPending = 0
+	}
+	// Money is always booked, but a case that cannot move, such as a
+	// withdrawn one, keeps its status
+	if CanTransitionCase(caseData.CaseStatus, status) {
+		if err := transitionCase(tx, caseData, status, reason, payment.PaymentReference, nil); err != nil {
+			return err
+		}
+	}
+
+	caseRepo := repository.NewCaseRepository(tx)
+	return caseRepo.UpdateCase(caseData)
+}
+
+// applyPaymentToCase knocks off bounce charges first, then interest and
+// finally principal, and advances the EMI counters by whole EMIs paid
+func applyPaymentToCase(caseData *models.Case, amount float64) {
+	remaining := amount
+	for _, outstanding := range []*float64{
+		&caseData.BounceCharges,
+		&caseData.InterestOutstanding,
+		&caseData.PrincipalOutstanding,
+	} {
+		paid := math.Min(remaining, *outstanding)
+		*outstanding = math.Round((*outstanding-paid)*100) / 100
+		remaining -= paid
+	}
+
+	if caseData.EMIAmount > 0 {
+		emisPaid := int(amount / caseData.EMIAmount)
+		emisPaid = min(emisPaid, caseData.EMIsPending)
+		caseData.EMIsPaidTillDate += emisPaid
+		caseData.EMIsPending -= emisPaid
+	}
+}
@@ -0,0 +1,369 @@
+package services
+
+import (
+	"backend/constants"
+	"backend/models"
+	"backend/repository"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidPaymentFile = errors.New("invalid payment file")
+
+// reconciliationRowParser reads the lines of a lender payment file with the
+// field readers of the case upload
+type reconciliationRowParser struct {
+	caseRowParser
+}
+
+func newReconciliationRowParser(header []string) (*reconciliationRowParser, error) {
+	columns, err := mapImportColumns(header, constants.PaymentReconciliationColumnAliases, constants.PaymentReconciliationRequiredColumns)
+	if err != nil {
+		return nil, err
+	}
+	return &reconciliationRowParser{caseRowParser{
+		columns:         columns,
+		requiredColumns: constants.PaymentReconciliationRequiredColumns,
+	}}, nil
+}
+
+// parse reads one line, marking it INVALID with the reasons it cannot be
+// accepted
+func (p *reconciliationRowParser) parse(row int, record []string) models.PaymentReconciliationLine {
+	p.row = row
+	p.errors = nil
+
+	line := models.PaymentReconciliationLine{
+		Row:       row,
+		LoanID:    p.text("loan_id", p.required(record, "loan_id"), constants.RECONCILIATION_MAX_LOAN_ID_LENGTH),
+		Amount:    p.boundedAmount(record, "amount", constants.RECONCILIATION_MAX_AMOUNT),
+		Mode:      p.text("mode", p.value(record, "mode"), constants.RECONCILIATION_MAX_MODE_LENGTH),
+		Reference: p.text("reference", p.value(record, "reference"), constants.RECONCILIATION_MAX_REFERENCE_LENGTH),
+	}
+	if paidAt := p.date(record, "date"); !paidAt.IsZero() {
+		line.PaidAt = &paidAt
+	}
+	if line.Amount == 0 && len(p.errors) == 0 {
+		p.fail("amount", "amount must be greater than zero")
+	}
+
+	if len(p.errors) > 0 {
+		reasons := make([]string, 0, len(p.errors))
+		for _, rowErr := range p.errors {
+			reasons = append(reasons, rowErr.Field+": "+rowErr.Reason)
+		}
+		line.Status = constants.RECONCILIATION_LINE_INVALID
+		line.Reason = strings.Join(reasons, "; ")
+	}
+	return line
+}
+
+// text fails a value longer than the column it is stored in, cutting it to
+// fit so the line can still be recorded as invalid
+func (p *reconciliationRowParser) text(field, value string, maxLength int) string {
+	if utf8.RuneCountInString(value) <= maxLength {
+		return value
+	}
+	p.fail(field, fmt.Sprintf("value cannot be longer than %d characters", maxLength))
+	return string([]rune(value)[:maxLength])
+}
+
+// boundedAmount reads an amount, failing one too large to be stored
+func (p *reconciliationRowParser) boundedAmount(record []string, field string, maxAmount float64) float64 {
+	amount := p.amount(record, field)
+	if math.Round(amount*100)/100 > maxAmount {
+		p.fail(field, fmt.Sprintf("amount cannot exceed %.2f", maxAmount))
+		return 0
+	}
+	return amount
+}
+
+// reconciliationKey identifies the bank transaction behind a line: its
+// reference when the bank gives one, otherwise its date, amount and mode
+func reconciliationKey(line models.PaymentReconciliationLine) string {
+	if line.Reference != "" {
+		return line.LoanID + "|" + line.Reference
+	}
+	return fmt.Sprintf("%s|%s|%.2f|%s", line.LoanID, line.PaidAt.Format(time.DateOnly), line.Amount, line.Mode)
+}
+
+// reconciliationEventID is stored as the payment's event ID so the same bank
+// transaction is never posted twice, even across files. Lines without a
+// reference can legitimately repeat within a file, so every repeat after
+// the first gets its own ID from its occurrence.
+func reconciliationEventID(key string, occurrence int) string {
+	if occurrence > 1 {
+		key = fmt.Sprintf("%s#%d", key, occurrence)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return constants.PAYMENT_SOURCE_BANK_RECONCILIATION + ":" + hex.EncodeToString(sum[:])
+}
+
+// ReconcilePayments matches a lender payment file to the open cases by loan
+// ID and posts every matched line as a payment. Lines repeating a reference
+// already in the file or a transaction already on record are flagged as
+// duplicates and lines without an open case as unmatched; neither is
+// posted. Lines without a reference that repeat an earlier line of the file
+// are posted but flagged as possible duplicates, since two equal payments on
+// one day are not unusual. Users without view_all_cases only reach their
+// lender's cases.
+func ReconcilePayments(env *models.Env, reader *csv.Reader, fileName string) (*models.PaymentReconciliation, error) {
+	lenderID, err := allocationLenderScope(env)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := readReconciliationLines(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	loanIDs := []string{}
+	for _, line := range lines {
+		if line.Status == "" {
+			loanIDs = append(loanIDs, line.LoanID)
+		}
+	}
+	openCases := map[string]models.Case{}
+	caseRepo := repository.NewCaseRepository(env.DbConn)
+	for start := 0; start < len(loanIDs); start += constants.DEFAULT_CASE_IMPORT_BATCH_SIZE {
+		batch, err := caseRepo.GetOpenCasesByLoanIDs(lenderID, loanIDs[start:min(start+constants.DEFAULT_CASE_IMPORT_BATCH_SIZE, len(loanIDs))])
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(openCases, batch)
+	}
+
+	reconciliation := &models.PaymentReconciliation{
+		FileName:  fileName,
+		LenderID:  lenderID,
+		CreatedBy: env.AuthDtos.User.ID,
+	}
+	err = env.DbConn.Transaction(func(tx *gorm.DB) error {
+		repo := repository.NewPaymentReconciliationRepository(tx)
+		reconciliation.Summary = datatypes.NewJSONType(models.ReconciliationSummary{})
+		if err := repo.CreateReconciliation(reconciliation); err != nil {
+			return err
+		}
+
+		// Lock every matched case up front, in ID order, so uploads and
+		// payments touching the same cases wait instead of deadlocking
+		caseIDs := []string{}
+		for _, line := range lines {
+			if matched, found := openCases[line.LoanID]; found && line.Status == "" {
+				caseIDs = append(caseIDs, matched.ID)
+			}
+		}
+		lockedCases, err := lockCases(tx, caseIDs)
+		if err != nil {
+			return err
+		}
+		casesByID := make(map[string]*models.Case, len(lockedCases))
+		for _, caseData := range lockedCases {
+			casesByID[caseData.ID] = caseData
+		}
+
+		summary := models.ReconciliationSummary{}
+		seen := map[string][]int{}
+		for i := range lines {
+			line := &lines[i]
+			line.ReconciliationID = reconciliation.ID
+			if line.Status == "" {
+				if err := reconcileLine(tx, line, openCases, casesByID, seen); err != nil {
+					return err
+				}
+			}
+			addReconciliationLine(&summary, line)
+		}
+
+		if err := repo.CreateLines(lines); err != nil {
+			return err
+		}
+		reconciliation.Summary = datatypes.NewJSONType(summary)
+		return repo.UpdateSummary(reconciliation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	AuditEntity(env, constants.AUDIT_ENTITY_PAYMENT_RECONCILIATION, reconciliation.ID)
+	AuditChange(env, nil, reconciliation)
+	return reconciliation, nil
+}
+
+// readReconciliationLines parses every line of the file. Lines that cannot
+// be read are kept as INVALID so the summary accounts for the whole file.
+func readReconciliationLines(reader *csv.Reader) ([]models.PaymentReconciliationLine, error) {
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: file is empty or missing header row", ErrInvalidPaymentFile)
+	}
+	parser, err := newReconciliationRowParser(header)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPaymentFile, err.Error())
+	}
+
+	lines := []models.PaymentReconciliationLine{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			row := len(lines) + 2
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				row = parseErr.StartLine
+			}
+			lines = append(lines, models.PaymentReconciliationLine{
+				Row:    row,
+				Status: constants.RECONCILIATION_LINE_INVALID,
+				Reason: err.Error(),
+			})
+			continue
+		}
+		row, _ := reader.FieldPos(0)
+		lines = append(lines, parser.parse(row, record))
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%w: file has no payment lines", ErrInvalidPaymentFile)
+	}
+	return lines, nil
+}
+
+// reconcileLine settles the outcome of a valid line inside tx, posting it
+// when it matches an open case and is not a duplicate. casesByID holds the
+// matched cases, already locked so a concurrent upload of the same file
+// cannot post the line as well; seen collects the rows of every key.
+func reconcileLine(tx *gorm.DB, line *models.PaymentReconciliationLine, openCases map[string]models.Case, casesByID map[string]*models.Case, seen map[string][]int) error {
+	key := reconciliationKey(*line)
+	earlierRows := seen[key]
+	seen[key] = append(earlierRows, line.Row)
+	if len(earlierRows) > 0 && line.Reference != "" {
+		line.Status = constants.RECONCILIATION_LINE_DUPLICATE
+		line.Reason = fmt.Sprintf("duplicate of row %d", earlierRows[0])
+		return nil
+	}
+
+	matched, found := openCases[line.LoanID]
+	if !found {
+		line.Status = constants.RECONCILIATION_LINE_UNMATCHED
+		line.Reason = "no open case for loan"
+		return nil
+	}
+	line.CaseID = &matched.ID
+	caseData := casesByID[matched.ID]
+
+	paymentRepo := repository.NewPaymentRepository(tx)
+	eventID := reconciliationEventID(key, len(earlierRows)+1)
+	exists, err := paymentRepo.PaymentEventExists(eventID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		line.Status = constants.RECONCILIATION_LINE_DUPLICATE
+		line.Reason = "already reconciled in an earlier file"
+		return nil
+	}
+	if line.Reference != "" {
+		existing, err := paymentRepo.GetPaymentByReference(caseData.ID, line.Reference)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			line.Status = constants.RECONCILIATION_LINE_DUPLICATE
+			line.Reason = fmt.Sprintf("reference already recorded as a %s payment", strings.ToLower(existing.Source))
+			line.PaymentID = &existing.ID
+			return nil
+		}
+	}
+
+	payment := &models.Payment{
+		CaseID:           caseData.ID,
+		Source:           constants.PAYMENT_SOURCE_BANK_RECONCILIATION,
+		EventID:          &eventID,
+		Mode:             line.Mode,
+		PaymentReference: line.Reference,
+		Amount:           line.Amount,
+		PaidAt:           *line.PaidAt,
+	}
+	if err := postPayment(tx, caseData, payment); err != nil {
+		return err
+	}
+	line.Status = constants.RECONCILIATION_LINE_POSTED
+	line.PaymentID = &payment.ID
+	if len(earlierRows) > 0 {
+		line.Status = constants.RECONCILIATION_LINE_POSSIBLE_DUPLICATE
+		line.Reason = fmt.Sprintf("same loan, date, amount and mode as row %d", earlierRows[0])
+	}
+	return nil
+}
+
+func addReconciliationLine(summary *models.ReconciliationSummary, line *models.PaymentReconciliationLine) {
+	summary.TotalLines++
+	summary.TotalAmount = roundAmount(summary.TotalAmount + line.Amount)
+	switch line.Status {
+	case constants.RECONCILIATION_LINE_POSTED:
+		summary.Posted++
+		summary.PostedAmount = roundAmount(summary.PostedAmount + line.Amount)
+	case constants.RECONCILIATION_LINE_POSSIBLE_DUPLICATE:
+		summary.Posted++
+		summary.PostedAmount = roundAmount(summary.PostedAmount + line.Amount)
+		summary.PossibleDuplicate++
+	case constants.RECONCILIATION_LINE_UNMATCHED:
+		summary.Unmatched++
+		summary.UnmatchedAmount = roundAmount(summary.UnmatchedAmount + line.Amount)
+	case constants.RECONCILIATION_LINE_DUPLICATE:
+		summary.Duplicate++
+		summary.DuplicateAmount = roundAmount(summary.DuplicateAmount + line.Amount)
+	case constants.RECONCILIATION_LINE_INVALID:
+		summary.Invalid++
+	}
+}
+
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// GetPaymentReconciliation returns a reconciliation and one page of its
+// lines, or nil when the user cannot see it
+func GetPaymentReconciliation(env *models.Env, id string, filter models.ReconciliationLineFilter) (*models.PaymentReconciliation, []models.PaymentReconciliationLine, int64, error) {
+	lenderID, err := allocationLenderScope(env)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	repo := repository.NewPaymentReconciliationRepository(env.DbConn)
+	reconciliation, err := repo.GetReconciliation(id, lenderID)
+	if err != nil || reconciliation == nil {
+		return nil, nil, 0, err
+	}
+	lines, total, err := repo.ListLines(id, filter)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return reconciliation, lines, total, nil
+}
+
+func ListPaymentReconciliations(env *models.Env, page, pageSize int) ([]models.PaymentReconciliation, int64, error) {
+	lenderID, err := allocationLenderScope(env)
+	if err != nil {
+		return nil, 0, err
+	}
+	repo := repository.NewPaymentReconciliationRepository(env.DbConn)
+	return repo.ListReconciliations(lenderID, page, pageSize)
+}
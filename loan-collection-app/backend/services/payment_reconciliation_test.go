@@ -0,0 +1,171 @@
+package services
+
+import (
+	"backend/constants"
+	"backend/models"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestReconciliationKey(t *testing.T) {
+	paidAt := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		line models.PaymentReconciliationLine
+		want string
+	}{
+		{
+			name: "reference wins over the other fields",
+			line: models.PaymentReconciliationLine{LoanID: "L1", Reference: "UTR1", Amount: 10, PaidAt: &paidAt, Mode: "UPI"},
+			want: "L1|UTR1",
+		},
+		{
+			name: "date, amount and mode without a reference",
+			line: models.PaymentReconciliationLine{LoanID: "L1", Amount: 2500.5, PaidAt: &paidAt, Mode: "NEFT"},
+			want: "L1|2026-03-04|2500.50|NEFT",
+		},
+		{
+			name: "amounts are compared to the paisa",
+			line: models.PaymentReconciliationLine{LoanID: "L1", Amount: 2500.499999, PaidAt: &paidAt},
+			want: "L1|2026-03-04|2500.50|",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reconciliationKey(tt.line); got != tt.want {
+				t.Errorf("reconciliationKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconciliationEventID(t *testing.T) {
+	first := reconciliationEventID("L1|UTR1", 1)
+	if !strings.HasPrefix(first, constants.PAYMENT_SOURCE_BANK_RECONCILIATION+":") {
+		t.Errorf("event ID %q is not prefixed with its source", first)
+	}
+	if again := reconciliationEventID("L1|UTR1", 1); again != first {
+		t.Errorf("event ID is not stable: %q and %q", first, again)
+	}
+	if second := reconciliationEventID("L1|UTR1", 2); second == first {
+		t.Errorf("repeated line shares the event ID of the first")
+	}
+	if other := reconciliationEventID("L2|UTR1", 1); other == first {
+		t.Errorf("different loans share an event ID")
+	}
+}
+
+func TestReconciliationRowParser(t *testing.T) {
+	header := []string{"Loan No", "Txn Amount", "Value Date", "Channel", "UTR"}
+	parser, err := newReconciliationRowParser(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		record     []string
+		wantStatus string
+		wantReason string
+	}{
+		{"valid line", []string{"L1", "1,200.00", "04/03/2026", "UPI", "UTR1"}, "", ""},
+		{"reference is optional", []string{"L1", "1200", "2026-03-04", "", ""}, "", ""},
+		{"zero amount", []string{"L1", "0", "2026-03-04", "", ""}, constants.RECONCILIATION_LINE_INVALID, "amount: amount must be greater than zero"},
+		{"negative amount", []string{"L1", "-5", "2026-03-04", "", ""}, constants.RECONCILIATION_LINE_INVALID, "amount: amount cannot be negative"},
+		{"missing fields", []string{"", "", "", "", ""}, constants.RECONCILIATION_LINE_INVALID, "loan_id: value is required; amount: value is required; date: value is required"},
+		{"bad date", []string{"L1", "10", "March 4", "", ""}, constants.RECONCILIATION_LINE_INVALID, `date: "March 4" is not a valid date, expected YYYY-MM-DD`},
+		{"loan ID too long", []string{strings.Repeat("L", 51), "10", "2026-03-04", "", ""}, constants.RECONCILIATION_LINE_INVALID, "loan_id: value cannot be longer than 50 characters"},
+		{"mode too long", []string{"L1", "10", "2026-03-04", strings.Repeat("M", 51), ""}, constants.RECONCILIATION_LINE_INVALID, "mode: value cannot be longer than 50 characters"},
+		{"reference too long", []string{"L1", "10", "2026-03-04", "", strings.Repeat("R", 256)}, constants.RECONCILIATION_LINE_INVALID, "reference: value cannot be longer than 255 characters"},
+		{"amount too large", []string{"L1", "100000000", "2026-03-04", "", ""}, constants.RECONCILIATION_LINE_INVALID, "amount: amount cannot exceed 99999999.99"},
+		{"amount rounding up too large", []string{"L1", "99999999.995", "2026-03-04", "", ""}, constants.RECONCILIATION_LINE_INVALID, "amount: amount cannot exceed 99999999.99"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := parser.parse(3, tt.record)
+			if line.Row != 3 || line.Status != tt.wantStatus || line.Reason != tt.wantReason {
+				t.Errorf("parse() = row %d, %q, %q; want row 3, %q, %q", line.Row, line.Status, line.Reason, tt.wantStatus, tt.wantReason)
+			}
+			if tt.wantStatus == "" && (line.PaidAt == nil || line.Amount != 1200) {
+				t.Errorf("parse() = %+v", line)
+			}
+		})
+	}
+
+	t.Run("values at the column limits", func(t *testing.T) {
+		record := []string{strings.Repeat("L", 50), "99999999.99", "2026-03-04", strings.Repeat("M", 50), strings.Repeat("R", 255)}
+		if line := parser.parse(3, record); line.Status != "" || line.Amount != constants.RECONCILIATION_MAX_AMOUNT {
+			t.Errorf("parse() = %q, %q, amount %v", line.Status, line.Reason, line.Amount)
+		}
+	})
+
+	t.Run("invalid values are cut to fit their columns", func(t *testing.T) {
+		record := []string{strings.Repeat("é", 60), "1e9", "2026-03-04", strings.Repeat("M", 60), strings.Repeat("R", 300)}
+		line := parser.parse(3, record)
+		if line.Status != constants.RECONCILIATION_LINE_INVALID {
+			t.Fatalf("parse() status = %q, want INVALID", line.Status)
+		}
+		if line.LoanID != strings.Repeat("é", 50) || len(line.Mode) != 50 || len(line.Reference) != 255 || line.Amount != 0 {
+			t.Errorf("parse() stored %d, %d, %d characters and amount %v", utf8.RuneCountInString(line.LoanID), len(line.Mode), len(line.Reference), line.Amount)
+		}
+	})
+
+	if _, err := newReconciliationRowParser([]string{"loan_id", "mode"}); err == nil {
+		t.Error("newReconciliationRowParser() accepted a header without amount and date")
+	}
+}
+
+func TestReadReconciliationLines(t *testing.T) {
+	file := "loan_id,amount,date\nL1,100,2026-03-04\nL2,\"unterminated,2026-03-04\n"
+	lines, err := readReconciliationLines(csv.NewReader(strings.NewReader(file)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("read %d lines, want 2", len(lines))
+	}
+	if lines[0].Row != 2 || lines[0].Status != "" {
+		t.Errorf("first line = %+v", lines[0])
+	}
+	if lines[1].Row != 3 || lines[1].Status != constants.RECONCILIATION_LINE_INVALID {
+		t.Errorf("unreadable line = %+v", lines[1])
+	}
+
+	for _, file := range []string{"", "loan_id,amount,date\n", "loan,amount\nL1,10\n"} {
+		if _, err := readReconciliationLines(csv.NewReader(strings.NewReader(file))); err == nil {
+			t.Errorf("readReconciliationLines(%q) accepted the file", file)
+		}
+	}
+}
+
+func TestAddReconciliationLine(t *testing.T) {
+	summary := models.ReconciliationSummary{}
+	for _, line := range []models.PaymentReconciliationLine{
+		{Status: constants.RECONCILIATION_LINE_POSTED, Amount: 100.1},
+		{Status: constants.RECONCILIATION_LINE_POSSIBLE_DUPLICATE, Amount: 100.2},
+		{Status: constants.RECONCILIATION_LINE_UNMATCHED, Amount: 50},
+		{Status: constants.RECONCILIATION_LINE_DUPLICATE, Amount: 25},
+		{Status: constants.RECONCILIATION_LINE_INVALID},
+	} {
+		addReconciliationLine(&summary, &line)
+	}
+
+	want := models.ReconciliationSummary{
+		TotalLines:        5,
+		TotalAmount:       275.3,
+		Posted:            2,
+		PostedAmount:      200.3,
+		PossibleDuplicate: 1,
+		Unmatched:         1,
+		UnmatchedAmount:   50,
+		Duplicate:         1,
+		DuplicateAmount:   25,
+		Invalid:           1,
+	}
+	if summary != want {
+		t.Errorf("summary = %+v, want %+v", summary, want)
+	}
+}
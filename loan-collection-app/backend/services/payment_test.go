@@ -0,0 +1,57 @@
+package services
+
+import (
+	"backend/models"
+	"testing"
+)
+
+func TestApplyPaymentToCase(t *testing.T) {
+	newCase := func() models.Case {
+		return models.Case{
+			BounceCharges:        500,
+			InterestOutstanding:  1000,
+			PrincipalOutstanding: 10000,
+			EMIAmount:            2000,
+			EMIsPaidTillDate:     4,
+			EMIsPending:          3,
+		}
+	}
+	tests := []struct {
+		name                          string
+		amount                        float64
+		bounce, interest, principal   float64
+		emisPaidTillDate, emisPending int
+	}{
+		{"bounce charges first", 300, 200, 1000, 10000, 4, 3},
+		{"then interest", 1200, 0, 300, 10000, 4, 3},
+		{"then principal", 2000, 0, 0, 9500, 5, 2},
+		{"paise are kept", 1500.55, 0, 0, 9999.45, 4, 3},
+		{"whole EMIs advance the counters", 4100, 0, 0, 7400, 6, 1},
+		{"EMIs stop at the pending count", 11500, 0, 0, 0, 7, 0},
+		{"overpayment leaves nothing outstanding", 20000, 0, 0, 0, 7, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caseData := newCase()
+			applyPaymentToCase(&caseData, tt.amount)
+			if caseData.BounceCharges != tt.bounce || caseData.InterestOutstanding != tt.interest || caseData.PrincipalOutstanding != tt.principal {
+				t.Errorf("outstanding = %v/%v/%v, want %v/%v/%v",
+					caseData.BounceCharges, caseData.InterestOutstanding, caseData.PrincipalOutstanding,
+					tt.bounce, tt.interest, tt.principal)
+			}
+			if caseData.EMIsPaidTillDate != tt.emisPaidTillDate || caseData.EMIsPending != tt.emisPending {
+				t.Errorf("EMIs paid/pending = %d/%d, want %d/%d",
+					caseData.EMIsPaidTillDate, caseData.EMIsPending, tt.emisPaidTillDate, tt.emisPending)
+			}
+		})
+	}
+
+	t.Run("no EMI amount", func(t *testing.T) {
+		caseData := newCase()
+		caseData.EMIAmount = 0
+		applyPaymentToCase(&caseData, 5000)
+		if caseData.EMIsPaidTillDate != 4 || caseData.EMIsPending != 3 {
+			t.Errorf("EMIs paid/pending = %d/%d, want 4/3", caseData.EMIsPaidTillDate, caseData.EMIsPending)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"backend/constants"
+	"backend/models"
+	"backend/repository"
+	"fmt"
+	"strings"
+)
+
+func ListPermissions(env *models.Env) ([]models.Permission, error) {
+	permissionRepo := repository.NewPermissionRepository(env.DbConn)
+	return permissionRepo.ListPermissions()
+}
+
+// SyncPermissions makes sure every permission checked by the API exists,
+// so it can be granted to roles
+func SyncPermissions(env *models.Env, names []string) error {
+	permissionRepo := repository.NewPermissionRepository(env.DbConn)
+	return permissionRepo.CreateMissingPermissions(names)
+}
+
+func GetPermissionsByUser(env *models.Env, userID string) ([]string, error) {
+	permissionRepo := repository.NewPermissionRepository(env.DbConn)
+	return permissionRepo.GetPermissionNamesByUser(userID)
+}
+
+func GetPermissionsByRole(env *models.Env, roleID string) ([]models.Permission, error) {
+	permissionRepo := repository.NewPermissionRepository(env.DbConn)
+	return permissionRepo.GetPermissionsByRole(roleID)
+}
+
+func AttachPermissionsToRole(env *models.Env, roleID string, names []string) error {
+	permissionRepo := repository.NewPermissionRepository(env.DbConn)
+	permissions, err := permissionRepo.GetPermissionsByNames(names)
+	if err != nil {
+		return err
+	}
+
+	found := map[string]string{}
+	for _, permission := range permissions {
+		found[permission.Name] = permission.ID
+	}
+	unknown := []string{}
+	permissionIDs := []string{}
+	for _, name := range names {
+		if id, ok := found[name]; ok {
+			permissionIDs = append(permissionIDs, id)
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown permissions: %s", strings.Join(unknown, ", "))
+	}
+
+	if err := permissionRepo.AttachPermissionsToRole(roleID, permissionIDs); err != nil {
+		return err
+	}
+
+	AuditEntity(env, constants.AUDIT_ENTITY_ROLE, roleID)
+	AuditChange(env, nil, map[string]interface{}{"permissions": names})
+	return nil
+}
+
+func DetachPermissionFromRole(env *models.Env, roleID, name string) error {
+	permissionRepo := repository.NewPermissionRepository(env.DbConn)
+	permissions, err := permissionRepo.GetPermissionsByNames([]string{name})
+	if err != nil {
+		return err
+	}
+	if len(permissions) == 0 {
+		return fmt.Errorf("unknown permission: %s", name)
+	}
+	if err := permissionRepo.DetachPermissionFromRole(roleID, permissions[0].ID); err != nil {
+		return err
+	}
+
+	AuditEntity(env, constants.AUDIT_ENTITY_ROLE, roleID)
+	AuditChange(env, map[string]interface{}{"permissions": []string{name}}, nil)
+	return nil
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"backend/constants"
+	"backend/models"
+	"backend/repository"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+var (
+	ErrNoReportScope = errors.New("user is not linked to a lender or agency")
+	ErrInvalidReport = errors.New("invalid report")
+)
+
+// reportScope limits a report to what the user may see: everything with
+// view_all_cases, otherwise the user's lender or, failing that, agency.
+// agencyID narrows the report for users not bound to an agency.
+func reportScope(env *models.Env, agencyID *string) (*string, *string, error) {
+	if utils.HasPermission(env, "view_all_cases") {
+		return nil, agencyID, nil
+	}
+	userRepo := repository.NewUserRepository(env.DbConn)
+	lenderID, err := userRepo.GetUserLenderID(env.AuthDtos.User.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if lenderID != "" {
+		return &lenderID, agencyID, nil
+	}
+	userAgencyID, err := userRepo.GetUserAgencyID(env.AuthDtos.User.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if userAgencyID == "" {
+		return nil, nil, ErrNoReportScope
+	}
+	return nil, &userAgencyID, nil
+}
+
+func ptpGraceDays() int {
+	if viper.IsSet("PTP_GRACE_DAYS") {
+		return max(viper.GetInt("PTP_GRACE_DAYS"), 0)
+	}
+	return constants.DEFAULT_PTP_GRACE_DAYS
+}
+
+// GetCollectionPerformance reports, per group, what was allocated and
+// collected in the period along with resolution, contact and promise to pay
+// rates
+func GetCollectionPerformance(env *models.Env, filter models.CollectionPerformanceFilter) ([]models.CollectionPerformanceRow, error) {
+	if !slices.Contains(constants.ReportGroupings, filter.GroupBy) {
+		return nil, fmt.Errorf("%w: group_by must be one of %v", ErrInvalidReport, constants.ReportGroupings)
+	}
+	if !filter.From.Before(filter.To) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidReport)
+	}
+	if filter.To.Sub(filter.From) > constants.MAX_REPORT_RANGE_DAYS*24*time.Hour {
+		return nil, fmt.Errorf("%w: period cannot exceed %d days", ErrInvalidReport, constants.MAX_REPORT_RANGE_DAYS)
+	}
+
+	var err error
+	filter.LenderID, filter.AgencyID, err = reportScope(env, filter.AgencyID)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := repository.NewReportRepository(env.DbConn)
+	rows, err := repo.GetCollectionPerformance(filter, ptpGraceDays(), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		row := &rows[i]
+		row.CollectionEfficiency = reportRate(row.CollectedAmount, row.AllocatedAmount)
+		row.ResolutionRate = reportRate(float64(row.ResolvedCases), float64(row.Cases))
+		row.ContactRate = reportRate(float64(row.ContactedCases), float64(row.Cases))
+		row.PTPKeptRate = reportRate(float64(row.PTPsKept), float64(row.PTPsDue))
+		row.AverageTrailsPerCase = reportRate(float64(row.Trails), float64(row.Cases))
+	}
+	return rows, nil
+}
+
+// reportRate divides, rounding to four places and treating an empty
+// denominator as zero
+func reportRate(numerator, denominator float64) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return math.Round(numerator/denominator*10000) / 10000
+}
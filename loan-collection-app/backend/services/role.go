@@ -1,6 +1,7 @@
 package services
 
 import (
+	"backend/constants"
 	"backend/models"
 	"backend/repository"
 	"errors"
@@ -13,7 +14,14 @@ func CreateRole(env *models.Env, roleName, description string) (*models.Role, er
 	if roleName == "" {
 		return nil, errors.New("role name cannot be empty")
 	}
-	return roleRepo.CreateRole(roleName, description)
+	role, err := roleRepo.CreateRole(roleName, description)
+	if err != nil {
+		return nil, err
+	}
+
+	AuditEntity(env, constants.AUDIT_ENTITY_ROLE, role.ID)
+	AuditChange(env, nil, roleAuditSnapshot(role.RoleName, role.Description))
+	return role, nil
 }
 
 func UpdateRole(env *models.Env, roleID uint64, roleName, description string) error {
@@ -21,12 +29,30 @@ func UpdateRole(env *models.Env, roleID uint64, roleName, description string) er
 		return errors.New("role name cannot be empty")
 	}
 	roleRepo := repository.NewRoleRepository(env.DbConn)
-	return roleRepo.UpdateRole(roleID, roleName, description)
+	before, err := roleRepo.GetRoleByID(roleID)
+	if err != nil {
+		return err
+	}
+	if err := roleRepo.UpdateRole(roleID, roleName, description); err != nil {
+		return err
+	}
+
+	AuditChange(env, roleAuditSnapshot(before.RoleName, before.Description), roleAuditSnapshot(roleName, description))
+	return nil
 }
 
 func DeleteRole(env *models.Env, roleID uint64) error {
 	roleRepo := repository.NewRoleRepository(env.DbConn)
-	return roleRepo.DeleteRole(roleID)
+	before, err := roleRepo.GetRoleByID(roleID)
+	if err != nil {
+		return err
+	}
+	if err := roleRepo.DeleteRole(roleID); err != nil {
+		return err
+	}
+
+	AuditChange(env, roleAuditSnapshot(before.RoleName, before.Description), nil)
+	return nil
 }
 
 func AssignRolesToUser(env *models.Env, userID string, roleList []string) error {
@@ -47,12 +73,24 @@ func AssignRolesToUser(env *models.Env, userID string, roleList []string) error
 
 func AssignRoleToUser(env *models.Env, userID, roleID string) error {
 	roleRepo := repository.NewRoleRepository(env.DbConn)
-	return roleRepo.AssignRoleToUser(userID, roleID)
+	if err := roleRepo.AssignRoleToUser(userID, roleID); err != nil {
+		return err
+	}
+
+	AuditEntity(env, constants.AUDIT_ENTITY_USER, userID)
+	AuditChange(env, nil, map[string]interface{}{"role_id": roleID})
+	return nil
 }
 
 func RemoveRoleFromUser(env *models.Env, userID, roleID string) error {
 	roleRepo := repository.NewRoleRepository(env.DbConn)
-	return roleRepo.RemoveRoleFromUser(userID, roleID)
+	if err := roleRepo.RemoveRoleFromUser(userID, roleID); err != nil {
+		return err
+	}
+
+	AuditEntity(env, constants.AUDIT_ENTITY_USER, userID)
+	AuditChange(env, map[string]interface{}{"role_id": roleID}, nil)
+	return nil
 }
 
 func GetRolesByUser(env *models.Env, userID string) ([]models.Role, error) {
@@ -64,3 +102,10 @@ func ListAllRoles(env *models.Env) ([]models.Role, error) {
 	roleRepo := repository.NewRoleRepository(env.DbConn)
 	return roleRepo.ListAllRoles()
 }
+
+func roleAuditSnapshot(roleName, description string) map[string]interface{} {
+	return map[string]interface{}{
+		"role_name":   roleName,
+		"description": description,
+	}
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"backend/constants"
+	"backend/models"
+	"backend/repository"
+	"errors"
+	"fmt"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidTrail = errors.New("invalid trail")
+
+func AddTrail(env *models.Env, trail *models.Trail) error {
+	if !slices.Contains(constants.TrailDispositions, trail.Disposition) {
+		return fmt.Errorf("%w: unknown disposition %s", ErrInvalidTrail, trail.Disposition)
+	}
+	if trail.Disposition == constants.DISPOSITION_PROMISE_TO_PAY && trail.PaymentDate == nil {
+		return fmt.Errorf("%w: payment date is required for a promise to pay", ErrInvalidTrail)
+	}
+
+	trail.UserID = env.AuthDtos.User.ID
+	return env.DbConn.Transaction(func(tx *gorm.DB) error {
+		caseRepo := repository.NewCaseRepository(tx)
+		caseData, err := caseRepo.GetCaseForUpdate(trail.CaseID)
+		if err != nil {
+			return err
+		}
+
+		trailRepo := repository.NewTrailRepository(tx)
+		if err := trailRepo.CreateTrail(trail); err != nil {
+			return err
+		}
+
+		status, reason := trailStatus(caseData.CaseStatus, trail.Disposition)
+		if status == "" || !CanTransitionCase(caseData.CaseStatus, status) {
+			return nil
+		}
+		if err := transitionCase(tx, caseData, status, reason, trail.Remarks, &trail.UserID); err != nil {
+			return err
+		}
+		return caseRepo.UpdateCase(caseData)
+	})
+}
+
+// trailStatus returns the status a case moves to when a trail with the given
+// disposition is recorded, or an empty status when it stays put. Only the
+// first contact starts work on a case; later calls leave a promise in place.
+func trailStatus(current, disposition string) (string, string) {
+	switch {
+	case disposition == constants.DISPOSITION_PROMISE_TO_PAY && current != constants.CASE_STATUS_PTP:
+		return constants.CASE_STATUS_PTP, constants.CASE_REASON_PROMISE_TO_PAY
+	case (disposition == constants.DISPOSITION_CONTACTED || disposition == constants.DISPOSITION_REFUSED_TO_PAY) &&
+		current == constants.CASE_STATUS_ASSIGNED_TO_AGENT:
+		return constants.CASE_STATUS_IN_PROGRESS, constants.CASE_REASON_CUSTOMER_CONTACTED
+	}
+	return "", ""
+}
+
+func GetTrails(env *models.Env, caseID string) ([]models.TrailDetails, error) {
+	trailRepo := repository.NewTrailRepository(env.DbConn)
+	return trailRepo.ListTrailsByCase(caseID)
+}
@@ -5,44 +5,34 @@ import (
 	"backend/models"
 	"backend/repository"
 
+	"errors"
 	"fmt"
-	"time"
-
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/spf13/viper"
 )
 
-func Login(env *models.Env, username, password string) (string, error) {
-	repository := repository.NewUserRepository(env.DbConn)
-	user, err := repository.ValidateCredentials(username, password)
-	if err != nil {
-		return "", err
-	}
+var ErrInvalidCredentials = errors.New("invalid credentials")
 
-	// Generate JWT token
-	token, err := generateJWTToken(user)
-	if err != nil {
-		return "", err
+// Login checks the credentials of an active user and issues a token pair.
+// Failed attempts are counted per username and per IP, and either one is
+// locked out for a while once it reaches its limit.
+func Login(env *models.Env, username, password, ip string) (*AuthTokens, error) {
+	if err := checkLoginLock(env, username, ip); err != nil {
+		return nil, err
 	}
 
-	return token, nil
-}
-
-func generateJWTToken(user *models.User) (string, error) {
-	jwtSecret := viper.GetString(constants.JWT_SECRET)
-	if jwtSecret == "" {
-		return "", fmt.Errorf("JWT secret is not configured")
+	repository := repository.NewUserRepository(env.DbConn)
+	user, err := repository.ValidateCredentials(username, password)
+	if err != nil || !user.IsActive {
+		if err := recordLoginFailure(env, username, ip); err != nil {
+			env.Logger.Error(err.Error())
+		}
+		return nil, ErrInvalidCredentials
 	}
 
-	claims := jwt.MapClaims{
-		"user_id":  user.ID,
-		"username": user.Username,
-		"email":    user.Email,
-		"exp":      time.Now().Add(time.Hour * 24).Unix(),
+	if err := recordLoginSuccess(env, username); err != nil {
+		env.Logger.Error(err.Error())
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(jwtSecret))
+	return issueTokens(env, user)
 }
 
 func CreateUser(env *models.Env, username, password string) (*models.User, error) {
@@ -65,10 +55,23 @@ func CreateUser(env *models.Env, username, password string) (*models.User, error
 		return nil, err
 	}
 
+	AuditEntity(env, constants.AUDIT_ENTITY_USER, user.ID)
+	AuditChange(env, nil, userAuditSnapshot(user))
 	return user, nil
 }
 
-func ListAllUsers(env *models.Env) ([]models.User, error) {
+func ListAllUsers(env *models.Env) ([]models.UserWithRoles, error) {
 	repository := repository.NewUserRepository(env.DbConn)
 	return repository.ListAllUsers()
 }
+
+// userAuditSnapshot is the state of a user kept in the audit log, without
+// the password hash
+func userAuditSnapshot(user *models.User) map[string]interface{} {
+	return map[string]interface{}{
+		"id":        user.ID,
+		"username":  user.Username,
+		"email":     user.Email,
+		"is_active": user.IsActive,
+	}
+}
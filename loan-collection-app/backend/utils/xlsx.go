@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`
+
+const xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+
+const xlsxSheetEnd = `</sheetData></worksheet>`
+
+// XLSXWriter streams a single sheet workbook row by row. Strings are written
+// inline so nothing has to be held back until the end; Close must be called
+// to complete the file.
+type XLSXWriter struct {
+	zip   *zip.Writer
+	sheet io.Writer
+	rows  int
+}
+
+func NewXLSXWriter(w io.Writer, sheetName string) (*XLSXWriter, error) {
+	archive := zip.NewWriter(w)
+	name, err := xlsxEscape(sheetName)
+	if err != nil {
+		return nil, err
+	}
+	parts := []struct{ path, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, name)},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/worksheets/sheet1.xml", xlsxSheetStart},
+	}
+
+	var part io.Writer
+	for _, p := range parts {
+		if part, err = archive.Create(p.path); err != nil {
+			return nil, err
+		}
+		if _, err = io.WriteString(part, p.content); err != nil {
+			return nil, err
+		}
+	}
+	// The sheet is the last part opened and stays open for the rows
+	return &XLSXWriter{zip: archive, sheet: part}, nil
+}
+
+// WriteRow appends a row. Integers and floats become numeric cells, booleans
+// boolean cells and everything else text; nil leaves the cell empty.
+func (x *XLSXWriter) WriteRow(cells []interface{}) error {
+	x.rows++
+	var row strings.Builder
+	fmt.Fprintf(&row, `<row r="%d">`, x.rows)
+	for i, cell := range cells {
+		ref := xlsxColumn(i) + strconv.Itoa(x.rows)
+		switch value := cell.(type) {
+		case nil:
+		case int:
+			fmt.Fprintf(&row, `<c r="%s"><v>%d</v></c>`, ref, value)
+		case int64:
+			fmt.Fprintf(&row, `<c r="%s"><v>%d</v></c>`, ref, value)
+		case float64:
+			fmt.Fprintf(&row, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(value, 'f', -1, 64))
+		case bool:
+			flag := 0
+			if value {
+				flag = 1
+			}
+			fmt.Fprintf(&row, `<c r="%s" t="b"><v>%d</v></c>`, ref, flag)
+		default:
+			text, err := xlsxEscape(fmt.Sprint(value))
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(&row, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, text)
+		}
+	}
+	row.WriteString("</row>")
+	_, err := io.WriteString(x.sheet, row.String())
+	return err
+}
+
+// Flush pushes buffered output to the underlying writer
+func (x *XLSXWriter) Flush() error {
+	return x.zip.Flush()
+}
+
+func (x *XLSXWriter) Close() error {
+	if _, err := io.WriteString(x.sheet, xlsxSheetEnd); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
+
+// xlsxColumn returns the letters of a zero based column index
+func xlsxColumn(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+func xlsxEscape(value string) (string, error) {
+	var escaped strings.Builder
+	if err := xml.EscapeText(&escaped, []byte(value)); err != nil {
+		return "", err
+	}
+	return escaped.String(), nil
+}
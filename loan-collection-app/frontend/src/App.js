@@ -8,7 +8,7 @@ import UserManagement from './components/UserManagement';
 import SideNavigation from './components/SideNavigation';
 import HomePage from './components/HomePage';
 import { getUserRolesAndPermissions } from './service/api';
-import { getAuthToken } from './service/auth';
+import { getAuthToken, logout, setSessionExpiredHandler } from './service/auth';
 import './App.css';
 import AgencyManagement from './components/AgencyManagement';
 import AgencyUserMapping from './components/AgencyUserMapping';
@@ -100,12 +100,19 @@ function App() {
   const [isAuthenticated, setIsAuthenticated] = useState(false);
   const [permissions, setPermissions] = useState([]);
 
-  const handleLogout = () => {
-    localStorage.removeItem('token');
+  const handleLogout = async () => {
+    await logout();
     setIsAuthenticated(false);
     setPermissions([]);
   };
 
+  useEffect(() => {
+    setSessionExpiredHandler(() => {
+      setIsAuthenticated(false);
+      setPermissions([]);
+    });
+  }, []);
+
   useEffect(() => {
     const checkAuth = async () => {
       const token = getAuthToken();
@@ -7,8 +7,7 @@ import {
   Paper,
   Button,
   TextField,
-  Checkbox,
-  FormControlLabel,
+  MenuItem,
   Card,
 } from "@mui/material";
 
@@ -46,24 +45,35 @@ const caseFields = [
   }
 ];
 
+const formatDate = (value) => value ? new Date(value).toLocaleDateString() : "N/A";
+
 const trailFields = [
-  { label: "Trail ID", key: "trail_id", default: "N/A" },
-  { 
-    label: "Contacted", 
-    key: "contacted", 
-    default: "No", 
-    format: (value) => value ? "Yes" : "No" 
-  },
-  { label: "Payment Date", key: "payment_date", default: "N/A" },
+  { label: "Logged On", key: "created_at", default: "N/A", format: formatDate },
+  { label: "Agent", key: "username", default: "N/A" },
+  { label: "Disposition", key: "disposition", default: "N/A" },
+  { label: "Payment Date", key: "payment_date", default: "N/A", format: formatDate },
+  { label: "Follow Up Date", key: "follow_up_date", default: "N/A", format: formatDate },
   { label: "Remarks", key: "remarks", default: "N/A" }
 ];
 
+// Must match constants.TrailDispositions on the backend
+const dispositions = [
+  { value: "CONTACTED", label: "Contacted" },
+  { value: "NOT_REACHABLE", label: "Not Reachable" },
+  { value: "WRONG_NUMBER", label: "Wrong Number" },
+  { value: "PROMISE_TO_PAY", label: "Promise to Pay" },
+  { value: "REFUSED_TO_PAY", label: "Refused to Pay" },
+  { value: "PAID", label: "Paid" }
+];
+
 function CaseDetails() {
   const { caseId } = useParams();
   const [caseInfo, setCaseInfo] = useState(null);
   const [trails, setTrails] = useState([]);
-  const [contacted, setContacted] = useState(false);
+  const [disposition, setDisposition] = useState("");
   const [paymentDate, setPaymentDate] = useState("");
+  const [followUpDate, setFollowUpDate] = useState("");
+  const [trailError, setTrailError] = useState("");
   const [remarks, setRemarks] = useState("");
   const [paymentLink, setPaymentLink] = useState("");
 
@@ -88,24 +98,32 @@ function CaseDetails() {
   }, [caseId]);
 
   const handleAddTrail = async () => {
+    if (!disposition) {
+      setTrailError("Select a disposition");
+      return;
+    }
+    if (disposition === "PROMISE_TO_PAY" && !paymentDate) {
+      setTrailError("Enter the promised payment date");
+      return;
+    }
     try {
-      const newTrail = {
-        contacted,
+      await postTrail(caseId, {
+        disposition,
         payment_date: paymentDate,
+        follow_up_date: followUpDate,
         remarks,
-      };
-      if (paymentDate && contacted.remarks) {
-        await postTrail(caseId, newTrail);
-        // Refresh trails
-        const tr = await getTrails(caseId);
-        setTrails(tr);
-        // Clear form
-        setContacted(false);
-        setPaymentDate("");
-        setRemarks("");
-      }
+      });
+      // Refresh trails
+      const tr = await getTrails(caseId);
+      setTrails(tr?.data || []);
+      // Clear form
+      setDisposition("");
+      setPaymentDate("");
+      setFollowUpDate("");
+      setRemarks("");
+      setTrailError("");
     } catch (err) {
-      console.error(err);
+      setTrailError(err.response?.data?.error || "Failed to add trail");
     }
   };
 
@@ -178,10 +196,17 @@ function CaseDetails() {
         <Typography variant="h5" gutterBottom>
           Log a Trail
         </Typography>
-        <FormControlLabel
-          control={<Checkbox checked={contacted} onChange={(e) => setContacted(e.target.checked)} />}
-          label="Contacted User"
-        />
+        <TextField
+          select
+          label="Disposition"
+          value={disposition}
+          onChange={(e) => setDisposition(e.target.value)}
+          style={{ minWidth: 240, display: "block", marginBottom: "16px" }}
+        >
+          {dispositions.map(({ value, label }) => (
+            <MenuItem key={value} value={value}>{label}</MenuItem>
+          ))}
+        </TextField>
         <TextField
           label="Promised Payment Date"
           type="date"
@@ -190,6 +215,14 @@ function CaseDetails() {
           InputLabelProps={{ shrink: true }}
           style={{ display: "block", marginBottom: "16px" }}
         />
+        <TextField
+          label="Follow Up Date"
+          type="date"
+          value={followUpDate}
+          onChange={(e) => setFollowUpDate(e.target.value)}
+          InputLabelProps={{ shrink: true }}
+          style={{ display: "block", marginBottom: "16px" }}
+        />
         <TextField
           label="Remarks"
           multiline
@@ -199,6 +232,9 @@ function CaseDetails() {
           fullWidth
           style={{ marginBottom: "16px" }}
         />
+        {trailError && (
+          <Typography color="error" style={{ marginBottom: "16px" }}>{trailError}</Typography>
+        )}
         <Button variant="contained" onClick={handleAddTrail}>
           Submit Trail
         </Button>
@@ -224,14 +260,14 @@ function CaseDetails() {
         </Typography>
         {trails && trails.length > 0 ? (
           trails.map((trail) => (
-            <Paper key={trail.trail_id} style={{ padding: 16, marginBottom: 8 }}>
+            <Paper key={trail.id} style={{ padding: 16, marginBottom: 8 }}>
               <div style={{display: 'flex', flexWrap: 'wrap', gap: 8}}>
                 {trailFields.map(({ label, key, default: defaultValue, format }) => {
                   const value = trail[key];
                   const displayValue = format ? format(value) : value ?? defaultValue;
                   return (
-                    <Paper style={{ padding: "8px", marginBottom: '8px' }}>
-                      <Typography key={key}>
+                    <Paper key={key} style={{ padding: "8px", marginBottom: '8px' }}>
+                      <Typography>
                         {label}: {displayValue}
                       </Typography>
                     </Paper>
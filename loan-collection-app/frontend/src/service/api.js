@@ -1,5 +1,5 @@
 import axios from 'axios';
-import { getAuthToken } from "./auth";
+import { attachAuthInterceptors } from "./auth";
 
 const BACKEND_HOST = process.env.REACT_APP_BACKEND_HOST || 'https://agent-app-179705954475.asia-south1.run.app';
 
@@ -7,30 +7,11 @@ const instance = axios.create({
   baseURL: `${BACKEND_HOST}/api/v1`,
 });
 
-// Add token to headers for all requests
-instance.interceptors.request.use(
-  (config) => {
-    const token = getAuthToken();
-    if (token) {
-      config.headers['Authorization'] = `Bearer ${token}`;
-    }
-    return config;
-  },
-  (error) => {
-    return Promise.reject(error);
-  }
-);
+// Add token to headers for all requests and refresh it when it expires
+attachAuthInterceptors(instance);
 
 
 export default instance;
-// Add token to headers for all requests
-instance.interceptors.request.use((config) => {
-  const token = getAuthToken();
-  if (token) {
-    config.headers.Authorization = `Bearer ${token}`;
-  }
-  return config;
-});
 
 export async function getCases() {
   const res = await instance.get("/cases");
@@ -52,8 +33,11 @@ export async function postTrail(caseId, trail) {
   return res.data;
 }
 
-export async function getPaymentLink(caseId) {
-  const res = await instance.get(`/cases/${caseId}/payment-link`);
+export async function getPaymentLink(caseId, amountType = "OVERDUE", amount = 0) {
+  const res = await instance.post(`/cases/${caseId}/payment-link`, {
+    amount_type: amountType,
+    amount,
+  });
   return res.data;
 }
 
@@ -4,8 +4,12 @@ import axios from "axios";
 const BACKEND_HOST = process.env.REACT_APP_BACKEND_HOST || 'https://agent-app-179705954475.asia-south1.run.app';
 
 const TOKEN_KEY = "app_token";
+const REFRESH_TOKEN_KEY = "app_refresh_token";
 const API_BASE_URL = `${BACKEND_HOST}/api/v1`;
 
+// Endpoints that must not trigger a token refresh when they answer 401
+const AUTH_PATHS = ["/login", "/token/refresh", "/logout"];
+
 // Create an axios instance with base URL and default headers
 const api = axios.create({
   baseURL: API_BASE_URL,
@@ -14,28 +18,101 @@ const api = axios.create({
   }
 });
 
-// Add a request interceptor to include token in headers
-api.interceptors.request.use(
-  (config) => {
-    const token = getAuthToken();
-    if (token) {
-      config.headers['Authorization'] = `Bearer ${token}`;
-    }
-    return config;
-  },
-  (error) => {
-    return Promise.reject(error);
-  }
-);
+let refreshRequest = null;
+let onSessionExpired = () => {};
 
 export function getAuthToken() {
   return localStorage.getItem(TOKEN_KEY);
 }
 
+export function getRefreshToken() {
+  return localStorage.getItem(REFRESH_TOKEN_KEY);
+}
+
+function storeTokens(data) {
+  localStorage.setItem(TOKEN_KEY, data.token);
+  localStorage.setItem(REFRESH_TOKEN_KEY, data.refresh_token);
+}
+
+function clearTokens() {
+  localStorage.removeItem(TOKEN_KEY);
+  localStorage.removeItem(REFRESH_TOKEN_KEY);
+}
+
+// Called when the session can no longer be refreshed and the user has to log in again
+export function setSessionExpiredHandler(handler) {
+  onSessionExpired = handler;
+}
+
+// Exchange the refresh token for a new token pair. Concurrent callers share
+// one request, since a refresh token can only be used once.
+export function refreshTokens() {
+  if (!refreshRequest) {
+    const refreshToken = getRefreshToken();
+    refreshRequest = (refreshToken
+      ? api.post("/token/refresh", { refresh_token: refreshToken })
+      : Promise.reject(new Error("No refresh token"))
+    )
+      .then((res) => {
+        storeTokens(res.data);
+        return res.data.token;
+      })
+      .finally(() => {
+        refreshRequest = null;
+      });
+  }
+  return refreshRequest;
+}
+
+// Send the access token with every request and, when it has expired or been
+// revoked, refresh it once and retry the request
+export function attachAuthInterceptors(instance) {
+  instance.interceptors.request.use(
+    (config) => {
+      const token = getAuthToken();
+      if (token) {
+        config.headers['Authorization'] = `Bearer ${token}`;
+      }
+      return config;
+    },
+    (error) => {
+      return Promise.reject(error);
+    }
+  );
+
+  instance.interceptors.response.use(
+    (response) => response,
+    async (error) => {
+      const config = error.config;
+      if (
+        error.response?.status !== 401 ||
+        !config ||
+        config._retried ||
+        AUTH_PATHS.includes(config.url)
+      ) {
+        return Promise.reject(error);
+      }
+
+      config._retried = true;
+      try {
+        const token = await refreshTokens();
+        config.headers['Authorization'] = `Bearer ${token}`;
+        return instance(config);
+      } catch (refreshError) {
+        clearTokens();
+        onSessionExpired();
+        return Promise.reject(error);
+      }
+    }
+  );
+}
+
+attachAuthInterceptors(api);
+
 export async function login(username, password) {
   try {
     const res = await api.post("/login", { username, password });
-    localStorage.setItem(TOKEN_KEY, res.data.token);
+    storeTokens(res.data);
     return res.data;
   } catch (error) {
     console.error("Login failed:", error);
@@ -43,8 +120,18 @@ export async function login(username, password) {
   }
 }
 
-export function logout() {
-  localStorage.removeItem(TOKEN_KEY);
+// Revoke the session on the server before forgetting the tokens, so a copied
+// token stops working as well
+export async function logout() {
+  try {
+    if (getAuthToken()) {
+      await api.post("/logout", { refresh_token: getRefreshToken() });
+    }
+  } catch (error) {
+    console.error("Logout failed:", error);
+  } finally {
+    clearTokens();
+  }
 }
 
 // Add a function to check if the user is authenticated
@@ -52,4 +139,4 @@ export function isAuthenticated() {
   return !!getAuthToken();
 }
 
-export default api;
\ No newline at end of file
+export default api;